@@ -2,22 +2,52 @@ package cmd
 
 import (
 	"log"
+	"os"
 
 	"github.com/atliod/gozelle/internal/core"
+	"github.com/atliod/gozelle/internal/db"
 	"github.com/spf13/cobra"
 )
 
 var AddCmd = &cobra.Command{
 	Use:   "add [path]",
 	Short: "Add a directory to the index",
-	Long:  `Add a directory to the index.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Add a directory to the index.
+
+The path is stored absolute and cleaned, so ./foo, /a/b/ and /a/b all name the
+same entry. With GOZELLE_RESOLVE_SYMLINKS=true symlinks are resolved as well. The
+path has to be an existing directory unless --force is given.
+
+--kind says how the directory was visited: manual (the default), cd (what the
+shell hooks pass), jump or interactive. Each adds its increment to the score,
+set with GOZELLE_INCREMENT_<KIND>, and is counted separately.
+
+Nothing is recorded in an incognito session (GOZELLE_INCOGNITO set, see
+gozelle incognito).`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if core.Incognito() {
+			return
+		}
 		path := args[0]
-		if err := core.Add(path); err != nil {
+		force, _ := cmd.Flags().GetBool("force")
+		kindName, _ := cmd.Flags().GetString("kind")
+		kind, err := db.ParseVisitKind(kindName)
+		if err != nil {
+			log.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := core.Add(path, core.AddOptions{Force: force, Kind: kind}); err != nil {
 			log.Println("Error adding path:", err)
 			return
 		}
 		core.Prune()
 	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+func init() {
+	AddCmd.Flags().Bool("force", false, "add the path even if it is not an existing directory")
+	AddCmd.Flags().String("kind", db.VisitManual.String(), "how the directory was visited: cd, jump, interactive or manual")
+	AddCmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(db.VisitKinds, cobra.ShellCompDirectiveNoFileComp))
 }
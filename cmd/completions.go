@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/atliod/gozelle/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -34,3 +35,23 @@ Example:
 	},
 	Hidden: true,
 }
+
+// completeStoredPaths is a ValidArgsFunction suggesting the best stored paths
+// for the keywords typed so far.
+func completeStoredPaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	paths := core.Complete(os.Getenv("GOZELLE_DATA_DIR"), args, toComplete, core.CompletionLimit)
+	return paths, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeStoredPath is like completeStoredPaths for commands taking a single
+// path, falling back to directory completion when nothing stored matches.
+func completeStoredPath(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	paths := core.Complete(os.Getenv("GOZELLE_DATA_DIR"), nil, toComplete, core.CompletionLimit)
+	if len(paths) == 0 {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	return paths, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
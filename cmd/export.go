@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the whole store with its metadata as JSON",
+	Long: `Export every entry of the store with all its metadata, sorted by path.
+
+Each entry has the fields of gozelle list --format json: path, score, frecency,
+last_visit, pinned, first_seen, visits, visits_by_kind, recent_visits, last_jump,
+source, tags and mark. --format ndjson prints one entry per line instead of an array,
+and --tag exports only the entries carrying a tag.
+
+Example:
+  gozelle export > gozelle-backup.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "json" && format != "ndjson" {
+			log.Println("Error: --format must be json or ndjson")
+			os.Exit(1)
+		}
+		tag, _ := cmd.Flags().GetString("tag")
+		if tag != "" {
+			if err := core.ValidateTag(tag); err != nil {
+				log.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+		opts := core.ListOptions{Sort: "path", Format: format, Filter: core.Filter{Tag: tag}}
+		if err := core.List(opts); err != nil {
+			log.Println("Error exporting directories:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringP("format", "f", "json", "output format: json or ndjson")
+	ExportCmd.Flags().String("tag", "", "only export directories carrying this tag")
+	ExportCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	ExportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"json", "ndjson"}, cobra.ShellCompDirectiveNoFileComp))
+}
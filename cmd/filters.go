@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags registers the --since, --before and --tag filters on cmd.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("since", "", "only include directories visited within this duration (e.g. 2h, 3d, 1w)")
+	cmd.Flags().String("before", "", "only include directories last visited before this date (YYYY-MM-DD) or duration ago")
+	cmd.Flags().String("tag", "", "only include directories carrying this tag")
+	cmd.RegisterFlagCompletionFunc("tag", completeTags)
+}
+
+// flagFilter builds a core.Filter from the flags registered by addFilterFlags.
+// An @tag among keywords sets the tag as well, and the remaining keywords are
+// returned.
+func flagFilter(cmd *cobra.Command, keywords []string) (core.Filter, []string, error) {
+	since, _ := cmd.Flags().GetString("since")
+	before, _ := cmd.Flags().GetString("before")
+	filter, err := core.NewTimeFilter(since, before)
+	if err != nil {
+		return filter, nil, err
+	}
+
+	filter.Tag, _ = cmd.Flags().GetString("tag")
+	if filter.Tag != "" {
+		if err := core.ValidateTag(filter.Tag); err != nil {
+			return filter, nil, err
+		}
+	}
+	return core.ScopeToTag(filter, keywords)
+}
+
+// completeTags is a flag completion function offering the stored tags.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return core.CompleteTags(os.Getenv("GOZELLE_DATA_DIR"), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
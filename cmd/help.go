@@ -21,19 +21,36 @@ USAGE:
 
 COMMANDS:
   query <keyword> Show matching directories without jumping
-  add <path>      Add a directory to the index
+  add <path>      Add a directory to the index (--kind cd, jump, interactive or manual)
   remove <path>   Remove a directory from the index
-  list           List all indexed directories
+  list [keywords] List indexed directories (--sort, --limit, --format, --template)
+  export          Export the whole store with its metadata as JSON (--format json or ndjson)
+  tag add|rm|ls   Group directories under tags; gz @tag <keyword> only considers the tagged ones
+  mark <name> [path]  Bookmark a directory, the current one by default; gz :name jumps to it
+  unmark <name>   Remove a bookmark
+  marks           List the bookmarks
+  boost <path> [factor]   Multiply the score of a directory (default factor: 2)
+  demote <path> [factor]  Divide the score of a directory (default factor: 2)
+  score set <path> <n>    Set the score of a directory
+  pin <path>      Rank a directory before every unpinned match
+  unpin <path>    Unpin a directory
+  ignore [pattern...]  Exclude directories matching glob patterns from tracking
+  incognito [on|off]  Pause or resume recording visits in this shell session
   help           Show this help message
 
 EXAMPLES:
   # Initialize shell integration
-  gozelle init <shell>  # e.g., bash, zsh, fish
+  gozelle init <shell>  # e.g., bash, zsh, fish, nushell, powershell, posix, ksh
+  gozelle init zsh --cmd cd --hook prompt  # replace cd, record at every prompt
+  gozelle setup         # add the init line to your shell's startup file
+  gozelle uninstall     # remove it again (--purge also deletes the data file)
 
   # Jump to a directory using a keyword
   gz <keyword> # Jump to the best match directory, e.g., 'gz projects' jumps to ~/Documents/projects
   # Show top match without jumping
   gozelle query <keyword>
+  # List every match, best first
+  gozelle query --list <keyword>
 
   # Add a directory manually
   gozelle add /some/path/to/add
@@ -44,8 +61,43 @@ EXAMPLES:
   # List all indexed directories
   gozelle list
 
+  # Back up the store with all its metadata
+  gozelle export > gozelle-backup.json
+
+  # Only consider directories visited recently (or before a date)
+  gozelle query --since 1d <keyword>
+  gozelle list --before 2025-01-01
+
+  # Tag directories and jump within a tag
+  gozelle tag add clientA ~/work/clientA-api ~/work/clientA-web
+  gz @clientA api
+  gozelle list --tag clientA
+
+  # Bookmark a directory and jump to it whatever the scores
+  gozelle mark infra ~/work/platform/infra
+  gz :infra
+
+  # Adjust the ranking by hand
+  gozelle boost ~/work/api 10
+  gozelle pin ~/work/api
+
+  # Never record node_modules or anything below /tmp
+  gozelle ignore node_modules '/tmp/**'
+
+  # Stop recording visits in this shell, and resume
+  gozelle incognito on
+  gozelle incognito off
+
 Environment Variables:
   GOZELLE_ECHO           Whether the top match is printed before navigation or no(false or true)
+  GOZELLE_AMBIGUITY_RATIO Prompt between matches within this ratio of the winner's frecency (0 disables, default: 0)
+  GOZELLE_PICKER         Picker for interactive mode: auto, fzf, sk, fzy, peco or builtin (default: auto)
+  GOZELLE_FZF_OPTS       Extra arguments for fzf (likewise GOZELLE_SK_OPTS, GOZELLE_FZY_OPTS, GOZELLE_PECO_OPTS, or GOZELLE_PICKER_OPTS for all)
+  GOZELLE_INCREMENT_<KIND> What a visit of KIND (CD, JUMP, INTERACTIVE, MANUAL) adds to a score (default: 1, 2, 2, 1)
+  GOZELLE_RESOLVE_SYMLINKS Whether add resolves symlinks in the paths it stores (false or true, default: false)
+  GOZELLE_EXCLUDE        Glob patterns of directories never recorded, separated like PATH (default: $HOME);
+                         used with the patterns in $XDG_CONFIG_HOME/gozelle/exclude added by gozelle ignore
+  GOZELLE_INCOGNITO      Set by gozelle incognito on; while it is non-empty no visit or jump is recorded
   GOZELLE_DATA_DIR           The path where the data is stored (default: $XDG_DATA_HOME/gozelle/db.gob or ~/.local/share/gozelle/db.gob)
 
 For more information, visit the project repository.
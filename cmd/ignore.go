@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var IgnoreCmd = &cobra.Command{
+	Use:   "ignore [pattern...]",
+	Short: "Exclude directories matching glob patterns from tracking",
+	Long: `Exclude directories matching glob patterns from tracking.
+
+The patterns are appended to $XDG_CONFIG_HOME/gozelle/exclude
+(~/.config/gozelle/exclude), one per line, and are used together with the ones in
+GOZELLE_EXCLUDE, a list separated like PATH. When GOZELLE_EXCLUDE is unset the home
+directory is excluded. ~ and environment variables are expanded in both.
+
+An absolute pattern is matched against the whole path, with * not crossing a /:
+/tmp/* excludes /tmp/xyz but not /tmp/xyz/src, while /tmp/** excludes both. A
+relative pattern matches any run of path components and so also everything below
+it: node_modules, .git or .git/refs.
+
+--purge also removes the stored entries matching the given patterns, or all the
+active patterns when none are given. Without arguments the active patterns are
+printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		purge, _ := cmd.Flags().GetBool("purge")
+		for _, pattern := range args {
+			added, err := core.AddExcludePattern(pattern)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			if !added {
+				fmt.Println("Already ignored:", pattern)
+			}
+		}
+
+		patterns, err := core.ExcludePatterns()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if len(args) == 0 && !purge {
+			for _, pattern := range patterns {
+				fmt.Println(pattern)
+			}
+			return
+		}
+		if !purge {
+			return
+		}
+
+		if len(args) > 0 {
+			patterns = nil
+			for _, pattern := range args {
+				patterns = append(patterns, core.ExpandPattern(pattern))
+			}
+		}
+		removed, err := core.PurgeExcluded(os.Getenv("GOZELLE_DATA_DIR"), patterns)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		for _, path := range removed {
+			fmt.Println("Removed", core.EscapePath(path))
+		}
+	},
+}
+
+func init() {
+	IgnoreCmd.Flags().Bool("purge", false, "remove the stored entries matching the patterns")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var IncognitoCmd = &cobra.Command{
+	Use:   "incognito [on|off]",
+	Short: "Pause recording visits in the current shell session",
+	Long: `Pause or resume recording visits in the current shell session.
+
+"gozelle incognito on" sets GOZELLE_INCOGNITO in the shell: the hooks stop adding
+directories, jumps with gz no longer bump scores and the prompt is prefixed with
+"(incognito) ". "gozelle incognito off" undoes this. Both change the shell itself,
+so they are handled by the gozelle function defined by the init script.
+
+Without an argument the state of the current session, on or off, is printed.`,
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"on", "off"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			if core.Incognito() {
+				fmt.Println("on")
+			} else {
+				fmt.Println("off")
+			}
+			return
+		}
+		fmt.Fprintln(os.Stderr, "Error: incognito", args[0], "has to change the shell session; load the shell integration with gozelle init to use it")
+		os.Exit(1)
+	},
+}
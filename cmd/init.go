@@ -3,8 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/atliod/gozelle/internal/core"
+	"github.com/atliod/gozelle/internal/shell"
 	"github.com/spf13/cobra"
 )
 
@@ -13,155 +15,95 @@ var InitCmd = &cobra.Command{
 	Use:   "init [shell]",
 	Short: "Print shell init script with hooks and completions",
 	Args:  cobra.MaximumNArgs(1),
+	Long: `Print the shell init script with hooks, the gz and gi functions and completions.
+
+The script also binds a widget that opens the interactive picker and inserts the
+chosen directory, shell-quoted, at the cursor. It is bound to Ctrl-G by default;
+use --key to pick another key (ctrl-<letter> or alt-<key>) or --key none to skip it.
+
+Tab completion is registered for gozelle itself and for gz and gi, which complete
+the typed keywords to the best matching stored paths. --no-completions leaves out
+the (large) completion script for the gozelle command.
+
+--cmd <name> defines <name> and <name>i instead of gz and gi; --cmd cd replaces cd
+itself. --hook chooses when visited directories are recorded: pwd (the default)
+whenever the working directory changes, prompt at every prompt, none never.
+
+The scripts are rendered from templates that can be overridden by placing a file
+with the same name, e.g. bash/widget.tmpl, in $XDG_CONFIG_HOME/gozelle/templates
+(~/.config/gozelle/templates). See internal/shell/templates in the source for the
+built-in ones and the fields they use.
+
+"posix" (also usable as "ksh") prints a script in plain POSIX sh for dash, ksh
+and mksh. It has no widget or completion; its pwd hook wraps cd and its prompt
+hook appends a command substitution to PS1.
+
+"nushell" prints a module to save and import with use. It records visits from an
+env_change.PWD (pwd) or pre_prompt (prompt) hook and completes keywords like the
+other shells.
+
+"powershell" wraps the prompt function to record visits, defines the commands as
+aliases of functions calling Set-Location and registers an argument completer.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		shell := "bash"
+		shellName := "bash"
 		if len(args) > 0 {
-			shell = args[0]
+			shellName = args[0]
 		}
 
-		switch shell {
-		case "bash":
+		name, _ := cmd.Flags().GetString("cmd")
+		hook, _ := cmd.Flags().GetString("hook")
+		key, _ := cmd.Flags().GetString("key")
+		noCompletions, _ := cmd.Flags().GetBool("no-completions")
+		opts, err := shell.NewOptions(name, hook, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Exe = initExecutable()
+		script, err := shell.Script(shellName, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if shellName == "bash" {
 			go core.CleanStore()
-			fmt.Print(bashHooksAndFunctions)
-			if err := RootCmd.GenBashCompletion(os.Stdout); err != nil {
-				fmt.Fprintf(os.Stderr, "Error generating bash completions: %v\n", err)
-				os.Exit(1)
-			}
+		}
+		fmt.Print(script)
+		if noCompletions {
+			return
+		}
+		switch shellName {
+		case "bash":
+			err = RootCmd.GenBashCompletion(os.Stdout)
 		case "zsh":
-			fmt.Print(zshHooksAndFunctions)
-			if err := RootCmd.GenZshCompletion(os.Stdout); err != nil {
-				fmt.Fprintf(os.Stderr, "Error generating zsh completions: %v\n", err)
-				os.Exit(1)
-			}
+			err = RootCmd.GenZshCompletion(os.Stdout)
 		case "fish":
-			fmt.Print(fishHooksAndFunctions)
-			if err := RootCmd.GenFishCompletion(os.Stdout, true); err != nil {
-				fmt.Fprintf(os.Stderr, "Error generating fish completions: %v\n", err)
-				os.Exit(1)
-			}
-		default:
-			fmt.Fprintf(os.Stderr, "Unsupported shell: %s\n", shell)
+			err = RootCmd.GenFishCompletion(os.Stdout, true)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating %s completions: %v\n", shellName, err)
 			os.Exit(1)
 		}
 	},
 }
 
-var bashHooksAndFunctions = `
-# Gozelle Bash init
-__gozelle_oldpwd="$(pwd)"
-
-__gozelle_hook() {
-    local retval=$?
-    local pwd_now="$(pwd)"
-    if [[ "$__gozelle_oldpwd" != "$pwd_now" ]]; then
-        __gozelle_oldpwd="$pwd_now"
-        command gozelle add "$pwd_now" >/dev/null 2>&1
-    fi
-    return $retval
+// initExecutable returns how the init script should call gozelle: by name when
+// it is on the PATH, otherwise by the path of the running binary.
+func initExecutable() string {
+	if _, err := exec.LookPath("gozelle"); err == nil {
+		return "gozelle"
+	}
+	if exe, err := os.Executable(); err == nil {
+		return exe
+	}
+	return "gozelle"
 }
 
-if [[ "$PROMPT_COMMAND" != *"__gozelle_hook"* ]]; then
-    PROMPT_COMMAND="__gozelle_hook;${PROMPT_COMMAND#;}"
-fi
-
-gz() {
-    if [ $# -eq 0 ]; then
-        cd ~
-    elif [ $# -eq 1 ] && [ "$1" = "-" ]; then
-        cd "${OLDPWD}"
-    elif [ $# -eq 1 ] && [ -d "$1" ]; then
-        cd "$1"
-    elif [ $# -eq 2 ] && [ "$1" = "--" ]; then
-        cd "$2"
-    else
-        target="$(command gozelle query "$@")" && cd "$target"
-    fi
+func init() {
+	InitCmd.Flags().String("cmd", "", "define <name> and <name>i instead of gz and gi (e.g. --cmd cd to replace cd)")
+	InitCmd.Flags().String("hook", shell.HookPwd, "when to record visited directories: none, prompt or pwd")
+	InitCmd.Flags().Bool("no-completions", false, "skip the completion script for the gozelle command")
+	InitCmd.RegisterFlagCompletionFunc("hook", cobra.FixedCompletions(shell.Hooks, cobra.ShellCompDirectiveNoFileComp))
+	InitCmd.Flags().String("key", shell.DefaultWidgetKey, "key for the directory-insertion widget, e.g. ctrl-g or alt-j, or none")
 }
-
-gi() {
-    target="$(command gozelle interactive "$@")" && cd "$target"
-}
-
-# Gozelle Bash completions
-_complete_gozelle() {
-    local cur prev words cword split
-    _init_completion -s || return
-
-    COMPREPLY=( $( COMP_CWORD=$COMP_CWORD \
-                   COMP_LINE=$COMP_LINE \
-                   COMP_POINT=$COMP_POINT \
-                   gozelle completions bash ) )
-}
-complete -F _complete_gozelle gozelle
-`
-
-var zshHooksAndFunctions = `
-# Gozelle Zsh init
-__gozelle_on_cd() {
-    command gozelle add "$PWD" >/dev/null 2>&1
-}
-
-autoload -Uz add-zsh-hook
-add-zsh-hook chpwd __gozelle_on_cd
-
-gz() {
-    if [ $# -eq 0 ]; then
-        cd ~
-    elif [ $# -eq 1 ] && [ "$1" = "-" ]; then
-        cd "${OLDPWD}"
-    elif [ $# -eq 1 ] && [ -d "$1" ]; then
-        cd "$1"
-    elif [ $# -eq 2 ] && [ "$1" = "--" ]; then
-        cd "$2"
-    else
-        target="$(command gozelle query "$@")" && cd "$target"
-    fi
-}
-
-gi() {
-    target="$(command gozelle interactive "$@")" && cd "$target"
-}
-
-autoload -U compinit && compinit
-`
-
-var fishHooksAndFunctions = `
-# Gozelle Fish init
-function __gozelle_prompt_hook --on-event fish_prompt
-    if not set -q __gozelle_oldpwd
-        set -g __gozelle_oldpwd $PWD
-    end
-
-    if test "$__gozelle_oldpwd" != "$PWD"
-        set -g __gozelle_oldpwd $PWD
-        gozelle add "$PWD" > /dev/null 2>&1
-    end
-end
-
-function gz
-    if test (count $argv) -eq 0
-        cd ~
-    else if test (count $argv) -eq 1 -a "$argv[1]" = "-"
-        cd "$OLDPWD"
-    else if test (count $argv) -eq 1 -a -d "$argv[1]"
-        cd "$argv[1]"
-    else if test (count $argv) -eq 2 -a "$argv[1]" = "--"
-        cd "$argv[2]"
-    else
-        set target (gozelle query $argv)
-        if test -n "$target"
-            cd "$target"
-        end
-    end
-end
-
-function gi
-    set target (gozelle interactive $argv)
-    if test -n "$target"
-        cd "$target"
-    end
-end
-
-# Gozelle Fish completions
-complete -c gozelle -f -a '(gozelle completions fish)'
-`
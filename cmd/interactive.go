@@ -4,30 +4,82 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/atliod/gozelle/internal/core"
+	"github.com/atliod/gozelle/internal/db"
 	"github.com/spf13/cobra"
 )
 
 var InteractiveCmd = &cobra.Command{
-	Use:   "interactive",
+	Use:   "interactive [keywords]",
 	Short: "Interactive mode for Gozelle",
 	Long: `Gozelle interactive mode allows you to jump to directories using fuzzy matching.
 You can use this command to quickly navigate to your frequently used directories without needing to remember their exact paths.
-This command is particularly useful for users who prefer a more visual and interactive way to select directories.`,
+This command is particularly useful for users who prefer a more visual and interactive way to select directories.
+
+Any keywords are used as the initial query, except an @tag keyword which, like
+--tag, only offers the directories carrying that tag. The picker is chosen with --picker or
+GOZELLE_PICKER: fzf, sk, fzy, peco or builtin. The default, auto, uses the first of
+those that is installed and falls back to the built-in picker. Extra arguments can be
+passed to external pickers with GOZELLE_PICKER_OPTS or GOZELLE_<PICKER>_OPTS, e.g.
+GOZELLE_FZF_OPTS="--height 40% --layout reverse".
+
+With --manage the store can be cleaned up from the picker: alt-d deletes, alt-p
+pins or unpins and alt-r resets the score of the selected entries (Tab to select
+several) or the highlighted one, and the list is reloaded after each action.
+
+Picking a directory counts as an interactive visit (see GOZELLE_INCREMENT_INTERACTIVE),
+except with --manage or in an incognito session.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Check if fzf is installed
-		core.CheckFzfInstalled()
+		filter, args, err := flagFilter(cmd, args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		print0, _ := cmd.Flags().GetBool("print0")
+		multi, _ := cmd.Flags().GetBool("multi")
+		manage, _ := cmd.Flags().GetBool("manage")
+		pickerName, _ := cmd.Flags().GetString("picker")
+
+		pickerName, err = core.ResolvePicker(pickerName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
 
 		// Call the interactive query function
-		result, err := core.QueryInteractive(os.Getenv("GOZELLE_DATA_DIR"), false)
+		selected, err := core.QueryInteractive(os.Getenv("GOZELLE_DATA_DIR"), core.InteractiveOptions{
+			Multi:  multi,
+			Filter: filter,
+			Picker: pickerName,
+			Query:  strings.Join(args, " "),
+			Manage: manage,
+		})
 		if err != nil {
-			fmt.Println("Error:", err)
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if !manage && !core.Incognito() {
+			if err := core.RecordVisit(os.Getenv("GOZELLE_DATA_DIR"), db.VisitInteractive, selected...); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
 		}
+		printPaths(selected, print0)
 		if os.Getenv("GOZELLE_ECHO") == "true" {
-			log.Println("jumped to:", result)
+			log.Println("jumped to:", selected[len(selected)-1])
 		}
 
 		core.Prune()
 	},
+	ValidArgsFunction: completeStoredPaths,
+}
+
+func init() {
+	addFilterFlags(InteractiveCmd)
+	addPrint0Flag(InteractiveCmd)
+	InteractiveCmd.Flags().BoolP("multi", "m", false, "allow selecting several directories (Tab to toggle)")
+	InteractiveCmd.Flags().Bool("manage", false, "bind alt-d (delete), alt-p (pin/unpin) and alt-r (reset score) to manage the store")
+	InteractiveCmd.Flags().String("picker", "", "picker to use: auto, fzf, sk, fzy, peco or builtin (default $GOZELLE_PICKER or auto)")
+	InteractiveCmd.RegisterFlagCompletionFunc("picker", cobra.FixedCompletions(core.Pickers, cobra.ShellCompDirectiveNoFileComp))
 }
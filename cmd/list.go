@@ -2,21 +2,63 @@ package cmd
 
 import (
 	"log"
+	"os"
 
 	"github.com/atliod/gozelle/internal/core"
 	"github.com/spf13/cobra"
 )
 
 var ListCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [keywords]",
 	Short: "List all directories in the store",
-	Long:  `List all directories in the store.`,
+	Long: `List the directories in the store, optionally restricted to those matching keywords.
+
+Entries are sorted by frecency by default. Use --format to print json, ndjson or tsv
+instead of the table, or --template to render each entry with a Go text/template.
+Templates see the fields .Path, .Score, .Frecency, .LastVisit, .Pinned, .FirstSeen,
+.Visits (the total), .VisitsByKind, .RecentVisits, .LastJump, .Source, .Tags and
+.Mark and the method .Ago. The json formats include the same metadata.
+
+Use --tag, or an @tag keyword, to list only the directories carrying a tag.
+
+Example:
+  gozelle list --sort recent --limit 10
+  gozelle list --format json api
+  gozelle list @clientA
+  gozelle list --template '{{.Ago}}	{{.Path}}'
+  gozelle list -z | xargs -0 du -sh`,
 	Run: func(cmd *cobra.Command, args []string) {
+		filter, args, err := flagFilter(cmd, args)
+		if err != nil {
+			log.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		opts := core.ListOptions{Keywords: args, Filter: filter}
+		opts.Sort, _ = cmd.Flags().GetString("sort")
+		opts.Reverse, _ = cmd.Flags().GetBool("reverse")
+		opts.Limit, _ = cmd.Flags().GetInt("limit")
+		opts.Format, _ = cmd.Flags().GetString("format")
+		opts.Template, _ = cmd.Flags().GetString("template")
+		opts.Print0, _ = cmd.Flags().GetBool("print0")
+
 		// Call the list function from core package
-		err := core.List()
+		err = core.List(opts)
 		if err != nil {
 			log.Println("Error listing directories:", err)
-			return
+			os.Exit(1)
 		}
 	},
 }
+
+func init() {
+	addFilterFlags(ListCmd)
+	addPrint0Flag(ListCmd)
+	ListCmd.Flags().String("sort", "frecency", "sort by frecency, score, recent, visits or path")
+	ListCmd.Flags().BoolP("reverse", "r", false, "reverse the sort order")
+	ListCmd.Flags().IntP("limit", "n", 0, "show at most this many entries (0 for all)")
+	ListCmd.Flags().StringP("format", "f", "table", "output format: table, json, ndjson or tsv")
+	ListCmd.Flags().String("template", "", "render each entry with this Go text/template")
+	ListCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(core.ListSorts, cobra.ShellCompDirectiveNoFileComp))
+	ListCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(core.ListFormats, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// ManageCmd backs the key bindings of `gozelle interactive --manage`.
+// The picker calls it to change the highlighted entries and to reload its list.
+var ManageCmd = &cobra.Command{
+	Use:   "manage [delete|pin|rescore|lines] [paths]",
+	Short: "Apply an interactive manage action to stored directories",
+	Long: `Apply an interactive manage action to stored directories.
+
+delete, pin and rescore take the paths to act on. lines prints the candidate
+list for the picker given with --picker, honouring --since, --before and --tag.`,
+	Args:   cobra.MinimumNArgs(1),
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		dataPath := os.Getenv("GOZELLE_DATA_DIR")
+		if args[0] == "lines" {
+			filter, _, err := flagFilter(cmd, nil)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			pickerName, _ := cmd.Flags().GetString("picker")
+			if err := core.WriteManageLines(os.Stdout, dataPath, pickerName, filter); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := core.ApplyManageAction(dataPath, args[0], args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addFilterFlags(ManageCmd)
+	ManageCmd.Flags().String("picker", core.PickerFzf, "picker to print candidate lines for")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var MarkCmd = &cobra.Command{
+	Use:   "mark <name> [path]",
+	Short: "Bookmark a directory under a name",
+	Long: `Bookmark a directory, the current one by default, under a name.
+
+gz :name then jumps to it whatever the scores. A directory has at most one
+bookmark and a name points to one directory, so marking again moves or renames
+it. Directories that are not stored yet are added, even if they are excluded
+from tracking (see gozelle ignore). Names are made of letters, digits, _, . and -.
+
+Example:
+  gozelle mark infra ~/work/platform/infra
+  gz :infra`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 2 {
+			path = args[1]
+		}
+		if err := core.SetMark(os.Getenv("GOZELLE_DATA_DIR"), args[0], path); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return core.CompleteMarks(os.Getenv("GOZELLE_DATA_DIR"), toComplete), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return completeStoredPath(cmd, nil, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var UnmarkCmd = &cobra.Command{
+	Use:   "unmark <name>",
+	Short: "Remove a bookmark",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.RemoveMark(os.Getenv("GOZELLE_DATA_DIR"), args[0]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return core.CompleteMarks(os.Getenv("GOZELLE_DATA_DIR"), toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var MarksCmd = &cobra.Command{
+	Use:   "marks",
+	Short: "List the bookmarks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		marks, err := core.Marks(os.Getenv("GOZELLE_DATA_DIR"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, mark := range marks {
+			fmt.Fprintf(tw, "%s%s\t%s\n", core.MarkPrefix, mark.Name, core.EscapePath(mark.Path))
+		}
+		tw.Flush()
+	},
+}
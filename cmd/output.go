@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// addPrint0Flag registers --print0/-z on cmd.
+func addPrint0Flag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("print0", "z", false, "terminate printed paths with NUL instead of a newline")
+}
+
+// printPaths writes paths to stdout, NUL-terminated when print0 is set.
+// Otherwise a single path is printed without a trailing newline so shell
+// functions can cd to it directly, and multiple paths one per line.
+func printPaths(paths []string, print0 bool) {
+	if print0 {
+		for _, p := range paths {
+			fmt.Print(p, "\x00")
+		}
+		return
+	}
+	if len(paths) == 1 {
+		fmt.Print(paths[0])
+		return
+	}
+	for _, p := range paths {
+		fmt.Println(p)
+	}
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+// PreviewCmd lists a directory for the interactive picker's preview pane.
+var PreviewCmd = &cobra.Command{
+	Use:    "preview [path]",
+	Short:  "List a directory for the interactive preview pane",
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.Preview(os.Stdout, args[0]); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/atliod/gozelle/internal/core"
 	"github.com/spf13/cobra"
@@ -11,19 +13,108 @@ import (
 var QueryCmd = &cobra.Command{
 	Use:   "query [keywords]",
 	Short: "Query for directories",
-	Long:  `Query for directories based on keywords.`,
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Query for directories based on keywords.
+
+Equal frecencies are broken in favour of the shorter path, then the more recent
+visit, then lexical order. With --ambiguity (or GOZELLE_AMBIGUITY_RATIO) set to a
+ratio such as 0.9, the matches within that fraction of the winner's frecency are
+offered in the interactive picker instead of jumping straight to the winner.
+
+With --list the matches are printed best first, one per line, without jumping or
+recording a visit. Keywords are optional then, and --limit caps the output. If
+the last keyword starts with @ or :, the tags or bookmark names it prefixes are
+printed instead.
+
+A keyword of the form @tag, or --tag, restricts the candidates to the entries
+carrying that tag, e.g. gz @clientA api. On its own, @tag jumps to the best of
+them.
+
+A query of a single :name keyword jumps straight to the bookmark of that name,
+see gozelle mark, whatever the scores.
+
+In an incognito session (GOZELLE_INCOGNITO set, see gozelle incognito) the jump
+is not recorded either.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		list, _ := cmd.Flags().GetBool("list")
+		if n := len(args); list && n > 0 {
+			// the shell completions ask for the names when an @tag or :bookmark is being typed
+			if names, ok := core.CompleteNames(os.Getenv("GOZELLE_DATA_DIR"), args[n-1]); ok {
+				for _, name := range names {
+					fmt.Println(name)
+				}
+				return
+			}
+		}
+
+		filter, args, err := flagFilter(cmd, args)
+		if err != nil {
+			log.Println("Error:", err)
+			os.Exit(1)
+		}
+		print0, _ := cmd.Flags().GetBool("print0")
+		if list {
+			limit, _ := cmd.Flags().GetInt("limit")
+			ranked, err := core.QueryList(args, os.Getenv("GOZELLE_DATA_DIR"), filter, limit)
+			if err != nil {
+				log.Println("Error:", err)
+				os.Exit(1)
+			}
+			terminator := "\n"
+			if print0 {
+				terminator = "\x00"
+			}
+			for _, m := range ranked {
+				fmt.Print(m.Path.Path, terminator)
+			}
+			return
+		}
+		opts := core.QueryOptions{Filter: filter, Incognito: core.Incognito()}
+		opts.Picker, _ = cmd.Flags().GetString("picker")
+		opts.AmbiguityRatio, _ = strconv.ParseFloat(os.Getenv("GOZELLE_AMBIGUITY_RATIO"), 64)
+		if cmd.Flags().Changed("ambiguity") {
+			opts.AmbiguityRatio, _ = cmd.Flags().GetFloat64("ambiguity")
+			if opts.AmbiguityRatio < 0 || opts.AmbiguityRatio > 1 {
+				log.Println("Error: --ambiguity must be between 0 and 1")
+				os.Exit(1)
+			}
+		}
+
 		keywords := args
 		path := os.Getenv("GOZELLE_DATA_DIR")
-		result := core.QueryTop(keywords, path)
+		result, err := core.QueryTopWithOptions(keywords, path, opts)
+		if err != nil {
+			log.Println("Error:", err)
+			os.Exit(1)
+		}
 		if result.Path == nil {
+			if print0 {
+				os.Exit(1)
+			}
+			fmt.Print("./")
 			log.Println("No match found")
 			return
 		}
+		printPaths([]string{result.Path.Path}, print0)
 		if os.Getenv("GOZELLE_ECHO") == "true" {
 			log.Println("jumped to:", result.Path.Path)
 		}
 		core.Prune()
 	},
+	ValidArgsFunction: completeStoredPaths,
+}
+
+func init() {
+	addFilterFlags(QueryCmd)
+	addPrint0Flag(QueryCmd)
+	QueryCmd.Flags().Float64("ambiguity", 0, "prompt when the runner-up is within this ratio of the winner (0 disables, default $GOZELLE_AMBIGUITY_RATIO)")
+	QueryCmd.Flags().Bool("list", false, "print all matches best first instead of jumping")
+	QueryCmd.Flags().IntP("limit", "n", 0, "with --list, print at most this many matches (0 for all)")
+	QueryCmd.Flags().String("picker", "", "picker used for the ambiguity prompt (default $GOZELLE_PICKER or auto)")
+	QueryCmd.RegisterFlagCompletionFunc("picker", cobra.FixedCompletions(core.Pickers, cobra.ShellCompDirectiveNoFileComp))
 }
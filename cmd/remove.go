@@ -20,4 +20,5 @@ var RemoveCmd = &cobra.Command{
 		}
 		core.Prune()
 	},
+	ValidArgsFunction: completeStoredPath,
 }
@@ -15,8 +15,24 @@ func init() {
 	RootCmd.AddCommand(AddCmd)
 	RootCmd.AddCommand(RemoveCmd)
 	RootCmd.AddCommand(ListCmd)
+	RootCmd.AddCommand(ExportCmd)
 	RootCmd.AddCommand(InteractiveCmd)
 	RootCmd.AddCommand(CompletionsCmd)
+	RootCmd.AddCommand(PreviewCmd)
+	RootCmd.AddCommand(ManageCmd)
+	RootCmd.AddCommand(SetupCmd)
+	RootCmd.AddCommand(UninstallCmd)
+	RootCmd.AddCommand(IncognitoCmd)
+	RootCmd.AddCommand(IgnoreCmd)
+	RootCmd.AddCommand(TagCmd)
+	RootCmd.AddCommand(MarkCmd)
+	RootCmd.AddCommand(UnmarkCmd)
+	RootCmd.AddCommand(MarksCmd)
+	RootCmd.AddCommand(BoostCmd)
+	RootCmd.AddCommand(DemoteCmd)
+	RootCmd.AddCommand(ScoreCmd)
+	RootCmd.AddCommand(PinCmd)
+	RootCmd.AddCommand(UnpinCmd)
 
 	RootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
 	RootCmd.SetHelpCommand(HelpCmd)
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var BoostCmd = &cobra.Command{
+	Use:   "boost <path> [factor]",
+	Short: "Multiply the score of a directory",
+	Long: `Multiply the score of a directory by factor, 2 by default, and print the new score.
+
+Directories that are not stored yet are added first, even if they are excluded
+from tracking (see gozelle ignore). See also demote, score set and pin.
+
+Example:
+  gozelle boost ~/work/api
+  gozelle boost ~/work/api 10`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		scaleScore(args, false)
+	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+var DemoteCmd = &cobra.Command{
+	Use:   "demote <path> [factor]",
+	Short: "Divide the score of a directory",
+	Long: `Divide the score of a directory by factor, 2 by default, and print the new score.
+
+Example:
+  gozelle demote ~/tmp/scratch 4`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		scaleScore(args, true)
+	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+// scaleScore runs boost, or demote if divide is set, for args <path> [factor].
+func scaleScore(args []string, divide bool) {
+	factor := core.DefaultBoostFactor
+	if len(args) == 2 {
+		var err error
+		factor, err = strconv.ParseFloat(args[1], 64)
+		if err != nil || factor <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid factor %q: must be a positive number\n", args[1])
+			os.Exit(1)
+		}
+	}
+	if divide {
+		factor = 1 / factor
+	}
+	score, err := core.ScaleScore(os.Getenv("GOZELLE_DATA_DIR"), args[0], factor)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%.2f\n", score)
+}
+
+var ScoreCmd = &cobra.Command{
+	Use:   "score",
+	Short: "Change the score of a directory",
+}
+
+var scoreSetCmd = &cobra.Command{
+	Use:   "set <path> <n>",
+	Short: "Set the score of a directory",
+	Long: `Set the score of a directory to n, adding it first if it is not stored yet.
+
+The score is what frecency is computed from: each recorded visit adds to it
+(see GOZELLE_INCREMENT_<KIND>) and its weight halves about every hour since the
+last visit. A newly added directory starts at 1.
+
+Example:
+  gozelle score set ~/work/api 50`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		score, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid score %q\n", args[1])
+			os.Exit(1)
+		}
+		if err := core.SetScore(os.Getenv("GOZELLE_DATA_DIR"), args[0], score); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+var PinCmd = &cobra.Command{
+	Use:   "pin <path>",
+	Short: "Pin a directory so that it wins whenever it matches",
+	Long: `Pin a directory, adding it first if it is not stored yet.
+
+A pinned directory ranks before every unpinned one matching the same query,
+whatever their scores, and the ambiguity prompt does not challenge it. Among
+pinned directories frecency decides as usual. Use unpin to undo it.
+
+Example:
+  gozelle pin ~/work/api`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.SetPinned(os.Getenv("GOZELLE_DATA_DIR"), args[0], true); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+var UnpinCmd = &cobra.Command{
+	Use:   "unpin <path>",
+	Short: "Unpin a directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.SetPinned(os.Getenv("GOZELLE_DATA_DIR"), args[0], false); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+func init() {
+	ScoreCmd.AddCommand(scoreSetCmd)
+}
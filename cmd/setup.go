@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/atliod/gozelle/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var SetupCmd = &cobra.Command{
+	Use:   "setup [shell]",
+	Short: "Add gozelle to your shell's startup file",
+	Long: `Add a marked block loading gozelle init to your shell's startup file.
+
+The shell is detected from $SHELL unless given as an argument or with --shell:
+bash (~/.bashrc), zsh (~/.zshrc), fish (config.fish), ksh ($ENV or ~/.kshrc),
+mksh ($ENV or ~/.mkshrc), posix ($ENV or ~/.profile) or powershell ($PROFILE).
+~/.profile is only read by login shells, so for dash and other POSIX shells set
+ENV to a startup file, e.g. export ENV=~/.shrc in ~/.profile, and run setup from
+a shell that has it. Running setup again is safe: the block is left alone, or replaced when
+the options changed. The init options --cmd, --hook, --key and --no-completions are
+passed on to gozelle init. Use --dry-run to see what would be written, and
+gozelle uninstall to remove the block again.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: shell.SetupShells,
+	Run: func(cmd *cobra.Command, args []string) {
+		shellName, rcFile, err := setupTarget(cmd, args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		name, _ := cmd.Flags().GetString("cmd")
+		hook, _ := cmd.Flags().GetString("hook")
+		key, _ := cmd.Flags().GetString("key")
+		if _, err := shell.NewOptions(name, hook, key); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		var initArgs []string
+		for _, flag := range []string{"cmd", "hook", "key"} {
+			if cmd.Flags().Changed(flag) {
+				value, _ := cmd.Flags().GetString(flag)
+				initArgs = append(initArgs, "--"+flag, core.ShellQuote(value))
+			}
+		}
+		if noCompletions, _ := cmd.Flags().GetBool("no-completions"); noCompletions {
+			initArgs = append(initArgs, "--no-completions")
+		}
+		block, err := shell.SetupBlock(shellName, initArgs)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		_, changed, err := shell.UpdateFile(rcFile, dryRun, func(content string) (string, bool) {
+			return shell.InsertBlock(content, block)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		switch {
+		case !changed:
+			fmt.Printf("%s is already set up for gozelle\n", rcFile)
+		case dryRun:
+			fmt.Printf("Would add to %s:\n\n%s", rcFile, block)
+		default:
+			fmt.Printf("Added gozelle to %s, restart your shell to use it\n", rcFile)
+		}
+		if shell.ProfileOnly(shellName) {
+			fmt.Println("Note: only login shells read ~/.profile; set ENV to a startup file, e.g. export ENV=~/.shrc, and run setup again to load gozelle in every interactive shell")
+		}
+	},
+}
+
+// setupTarget returns the shell named in args or by --shell, or detected from
+// $SHELL, and its startup file.
+func setupTarget(cmd *cobra.Command, args []string) (string, string, error) {
+	shellName, _ := cmd.Flags().GetString("shell")
+	if len(args) > 0 {
+		if shellName != "" && shellName != args[0] {
+			return "", "", fmt.Errorf("shell given both as %q and --shell %q", args[0], shellName)
+		}
+		shellName = args[0]
+	}
+	var err error
+	if shellName == "" {
+		if shellName, err = shell.DetectShell(os.Getenv("SHELL")); err != nil {
+			return "", "", err
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	rcFile, err := shell.RcFile(shellName, home)
+	if err != nil {
+		return "", "", err
+	}
+	return shellName, rcFile, nil
+}
+
+func init() {
+	SetupCmd.Flags().String("shell", "", "shell to set up instead of the one in $SHELL: "+strings.Join(shell.SetupShells, ", "))
+	SetupCmd.Flags().Bool("dry-run", false, "print the block and the file it would go to without changing anything")
+	SetupCmd.Flags().String("cmd", "", "passed to gozelle init: define <name> and <name>i instead of gz and gi")
+	SetupCmd.Flags().String("hook", shell.HookPwd, "passed to gozelle init: none, prompt or pwd")
+	SetupCmd.Flags().String("key", shell.DefaultWidgetKey, "passed to gozelle init: key for the directory-insertion widget")
+	SetupCmd.Flags().Bool("no-completions", false, "passed to gozelle init: skip the completion script for the gozelle command")
+	SetupCmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions(shell.SetupShells, cobra.ShellCompDirectiveNoFileComp))
+	SetupCmd.RegisterFlagCompletionFunc("hook", cobra.FixedCompletions(shell.Hooks, cobra.ShellCompDirectiveNoFileComp))
+}
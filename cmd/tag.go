@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/atliod/gozelle/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var TagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Group directories under tags",
+	Long: `Group directories under tags, e.g. one per client or project.
+
+A tag scopes jumps and listings: gz @clientA api only considers the directories
+tagged clientA, and list, export and interactive take --tag. Tag names are made of
+letters, digits, _, . and -.
+
+Example:
+  gozelle tag add clientA ~/work/clientA-api ~/work/clientA-web
+  gozelle tag rm clientA ~/work/clientA-web
+  gozelle tag ls`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <tag> <path...>",
+	Short: "Tag directories, adding them to the store if needed",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.TagPaths(os.Getenv("GOZELLE_DATA_DIR"), args[0], args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: completeTagThenPaths,
+}
+
+var tagRmCmd = &cobra.Command{
+	Use:   "rm <tag> [path...]",
+	Short: "Untag directories, or every directory when no paths are given",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := core.UntagPaths(os.Getenv("GOZELLE_DATA_DIR"), args[0], args[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		if removed == 0 {
+			fmt.Fprintln(os.Stderr, "No directory was tagged", args[0])
+		}
+	},
+	ValidArgsFunction: completeTagThenPaths,
+}
+
+var tagLsCmd = &cobra.Command{
+	Use:   "ls [path]",
+	Short: "List the tags with their number of directories, or the tags of a path",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dataPath := os.Getenv("GOZELLE_DATA_DIR")
+		if len(args) == 1 {
+			tags, err := core.PathTags(dataPath, args[0])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			for _, tag := range tags {
+				fmt.Println(tag)
+			}
+			return
+		}
+
+		tags, err := core.Tags(dataPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, tag := range tags {
+			fmt.Fprintf(tw, "%s\t%d\n", tag.Name, tag.Count)
+		}
+		tw.Flush()
+	},
+	ValidArgsFunction: completeStoredPath,
+}
+
+// completeTagThenPaths completes a tag as the first argument and stored paths
+// after it.
+func completeTagThenPaths(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeTags(cmd, args, toComplete)
+	}
+	paths := core.Complete(os.Getenv("GOZELLE_DATA_DIR"), nil, toComplete, core.CompletionLimit)
+	if len(paths) == 0 {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	return paths, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+func init() {
+	TagCmd.AddCommand(tagAddCmd, tagRmCmd, tagLsCmd)
+}
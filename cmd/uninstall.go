@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/atliod/gozelle/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var UninstallCmd = &cobra.Command{
+	Use:   "uninstall [shell]",
+	Short: "Remove gozelle from your shell's startup files",
+	Long: `Remove the block added by gozelle setup from the startup files of every
+supported shell, or only from the given shell's. With --purge the data file at
+GOZELLE_DATA_DIR is deleted as well.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: shell.SetupShells,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		purge, _ := cmd.Flags().GetBool("purge")
+
+		shells := shell.SetupShells
+		if len(args) > 0 {
+			shells = args
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		removed := map[string]bool{}
+		for _, shellName := range shells {
+			rcFile, err := shell.RcFile(shellName, home)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			if removed[rcFile] {
+				continue
+			}
+			_, changed, err := shell.UpdateFile(rcFile, dryRun, shell.RemoveBlock)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			if changed {
+				removed[rcFile] = true
+				if dryRun {
+					fmt.Println("Would remove gozelle from", rcFile)
+				} else {
+					fmt.Println("Removed gozelle from", rcFile)
+				}
+			}
+		}
+		if len(removed) == 0 {
+			fmt.Println("No gozelle setup block found")
+		}
+
+		if !purge {
+			return
+		}
+		dataFile := os.Getenv("GOZELLE_DATA_DIR")
+		if dryRun {
+			fmt.Println("Would delete", dataFile)
+			return
+		}
+		if err := os.Remove(dataFile); errors.Is(err, os.ErrNotExist) {
+			fmt.Println("No data file at", dataFile)
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		} else {
+			fmt.Println("Deleted", dataFile)
+		}
+	},
+}
+
+func init() {
+	UninstallCmd.Flags().Bool("dry-run", false, "report what would be removed without changing anything")
+	UninstallCmd.Flags().Bool("purge", false, "also delete the data file at GOZELLE_DATA_DIR")
+}
@@ -2,23 +2,48 @@ package core
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/atliod/gozelle/internal/db"
 )
 
-func Add(path string) error {
-	database, err := db.NewDirectoryManager()
+// AddOptions configures Add.
+type AddOptions struct {
+	Force bool         // record path even if it is not an existing directory
+	Kind  db.VisitKind // how the directory was visited
+}
+
+// Add records a visit of opts.Kind to path, stored in the form returned by CanonicalPath,
+// unless it is matched by an exclusion pattern, see ExcludePatterns.
+func Add(path string, opts AddOptions) error {
+	if err := ValidatePath(path); err != nil {
+		return err
+	}
+	path, err := CanonicalPath(path, ResolveSymlinks())
 	if err != nil {
-		fmt.Println("Error initializing database:", err)
-		panic(err)
+		return err
+	}
+	if !opts.Force {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("not a directory: %s", path)
+		}
+	}
+	if err := checkExcluded(path); err != nil {
+		return err
 	}
 
-	err = database.Add(path)
+	database, err := db.NewDirectoryManager()
 	if err != nil {
-		fmt.Println("Error adding path:", err)
+		fmt.Println("Error initializing database:", err)
 		panic(err)
 	}
 
+	database.Visit(path, opts.Kind, Increment(opts.Kind))
+
 	err = database.Save()
 	if err != nil {
 		fmt.Println("Error saving database:", err)
@@ -27,3 +52,37 @@ func Add(path string) error {
 	fmt.Print("Path added successfully: ", path, "\n")
 	return nil
 }
+
+// checkExcluded fails if path is matched by one of ExcludePatterns.
+func checkExcluded(path string) error {
+	patterns, err := ExcludePatterns()
+	if err != nil {
+		return err
+	}
+	if pattern := Excluded(path, patterns); pattern != "" {
+		return fmt.Errorf("%s is excluded by %q", path, pattern)
+	}
+	return nil
+}
+
+// storedOrAdded returns the entry of path in database. A path that is not
+// stored yet is recorded as a manual visit, provided it is an existing
+// directory. Exclusion patterns and incognito are not consulted: they only
+// keep Add from tracking directories automatically, while the commands
+// tagging, marking or rescoring a directory name it explicitly.
+func storedOrAdded(database *db.DirectoryManager, path string) (*db.Directory, error) {
+	if err := ValidatePath(path); err != nil {
+		return nil, err
+	}
+	if dir, err := database.Get(storedPath(database, path)); err == nil {
+		return dir, nil
+	}
+	path, err := CanonicalPath(path, ResolveSymlinks())
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a stored path or directory: %s", path)
+	}
+	return database.Visit(path, db.VisitManual, Increment(db.VisitManual)), nil
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// newTestTree creates the directories rel below a fresh temporary directory and
+// returns the path of a store file there and the absolute directories. The
+// home and config directories are empty temporary ones and no exclusion
+// pattern or incognito setting is inherited from the environment.
+func newTestTree(t *testing.T, rel ...string) (string, []string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(ExcludeEnv, "")
+	t.Setenv(IncognitoEnv, "")
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs := make([]string, len(rel))
+	for i, r := range rel {
+		dirs[i] = filepath.Join(root, r)
+		if err := os.MkdirAll(dirs[i], 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(root, "db.gob"), dirs
+}
+
+func TestAdd(t *testing.T) {
+	dataPath, dirs := newTestTree(t, "project")
+	dir, project := filepath.Dir(dataPath), dirs[0]
+	t.Setenv("GOZELLE_DATA_DIR", dataPath)
+	t.Chdir(dir)
+
+	for _, p := range []string{"./project", project + "/", project} {
+		if err := Add(p, AddOptions{}); err != nil {
+			t.Fatalf("Add(%q) returned error: %v", p, err)
+		}
+	}
+	if err := Add(filepath.Join(dir, "missing"), AddOptions{}); err == nil {
+		t.Fatal("expected Add to refuse a missing directory")
+	}
+	if err := Add(filepath.Join(dir, "missing"), AddOptions{Force: true}); err != nil {
+		t.Fatalf("expected --force to add a missing directory, got %v", err)
+	}
+	Prune()
+
+	dm, err := db.NewDirectoryManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dm.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(dm.Entries))
+	}
+	entry, err := dm.Get(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Score != 3*DefaultIncrements[db.VisitCd] || entry.Visits.Cd != 3 {
+		t.Fatalf("expected the three adds to count on one entry, got score %v and visits %+v", entry.Score, entry.Visits)
+	}
+}
+
+func TestRecordVisit(t *testing.T) {
+	dataPath := filepath.Join(t.TempDir(), "db.gob")
+	t.Setenv(IncrementEnv(db.VisitManual), "5")
+
+	if err := RecordVisit(dataPath, db.VisitManual, "/a", "/b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordVisit(dataPath, db.VisitInteractive, "/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	dm, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dm.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(dm.Entries))
+	}
+	a, _ := dm.Get("/a")
+	want := 5 + DefaultIncrements[db.VisitInteractive]
+	if a.Score != want || a.Visits.Manual != 1 || a.Visits.Interactive != 1 {
+		t.Fatalf("expected score %v with one manual and one interactive visit, got %+v", want, a)
+	}
+}
+
+func TestStoredOrAdded(t *testing.T) {
+	dataPath, dirs := newTestTree(t, "home")
+	home := dirs[0]
+	t.Setenv("HOME", home)
+	t.Setenv(ExcludeEnv, "")
+	os.Unsetenv(ExcludeEnv) // exclude the home directory by default
+	t.Setenv(IncognitoEnv, "1")
+	if err := checkExcluded(home); err == nil {
+		t.Fatal("expected the home directory to be excluded from tracking")
+	}
+
+	// naming a directory explicitly overrides exclusions and incognito
+	if err := SetMark(dataPath, "home", home); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPinned(dataPath, home, true); err != nil {
+		t.Fatal(err)
+	}
+	dm, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := dm.Get(home)
+	if err != nil || dir.Mark != "home" || !dir.Pinned || len(dm.Entries) != 1 {
+		t.Fatalf("expected the home directory to be marked and pinned, got %+v, %v", dir, err)
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// CompletionLimit is the number of stored paths offered when completing keywords.
+const CompletionLimit = 10
+
+// QueryList returns the entries matching keywords, best first, without
+// recording a visit. With no keywords every entry allowed by filter is returned.
+// An @tag among keywords restricts the entries to those carrying the tag.
+// A limit of 0 returns all of them.
+func QueryList(keywords []string, path string, filter Filter, limit int) ([]ScoredMatch, error) {
+	filter, keywords, err := ScopeToTag(filter, keywords)
+	if err != nil {
+		return nil, err
+	}
+	database, err := db.NewDirectoryManagerWithPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranked []ScoredMatch
+	if len(keywords) == 0 {
+		ranked = RankByFrecency(filterEntries(database.Entries, filter))
+	} else {
+		ranked = rankMatches(database.Entries, keywords, filter)
+	}
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// Complete returns the stored paths to suggest for the word being completed,
+// best first. args are the words already typed and toComplete the partial
+// word: an entry is offered if toComplete is a prefix of its path or if it
+// matches args followed by toComplete as keywords. An @tag among args limits
+// the entries to those carrying the tag, and a toComplete starting with @ or :
+// is completed by CompleteNames instead.
+func Complete(path string, args []string, toComplete string, limit int) []string {
+	if names, ok := CompleteNames(path, toComplete); ok {
+		return names
+	}
+	filter, args, err := ScopeToTag(Filter{}, args)
+	if err != nil {
+		return nil
+	}
+
+	database, err := db.NewDirectoryManagerWithPath(path)
+	if err != nil {
+		return nil
+	}
+
+	keywords := append(append([]string{}, args...), toComplete)
+	var dirs []*db.Directory
+	for _, dir := range database.Entries {
+		if !filter.Allows(dir) {
+			continue
+		}
+		if (toComplete != "" && strings.HasPrefix(dir.Path, toComplete)) || MatchByKeywords(dir.Path, keywords) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	ranked := RankByFrecency(dirs)
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	paths := make([]string, len(ranked))
+	for i, m := range ranked {
+		paths[i] = m.Path.Path
+	}
+	return paths
+}
+
+// CompleteNames completes a word starting with @ to the stored tags and one
+// starting with : to the bookmark names, keeping the prefix. It reports false
+// for any other word.
+func CompleteNames(path, word string) ([]string, bool) {
+	var prefix string
+	var names []string
+	if name, ok := strings.CutPrefix(word, TagPrefix); ok {
+		prefix, names = TagPrefix, CompleteTags(path, name)
+	} else if name, ok := strings.CutPrefix(word, MarkPrefix); ok {
+		prefix, names = MarkPrefix, CompleteMarks(path, name)
+	} else {
+		return nil, false
+	}
+	for i := range names {
+		names[i] = prefix + names[i]
+	}
+	return names, true
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestComplete(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+	dm.QueryDummyData()
+
+	paths := Complete(dm.FilePath, nil, "test", 0)
+	if len(paths) != 4 {
+		t.Fatalf("expected 4 completions, got %v", paths)
+	}
+	if paths[0] != "/path1/test" {
+		t.Fatalf("expected the highest frecency first, got %v", paths)
+	}
+
+	paths = Complete(dm.FilePath, []string{"path2"}, "te", 0)
+	if len(paths) != 1 || paths[0] != "/path2/test" {
+		t.Fatalf("expected /path2/test, got %v", paths)
+	}
+
+	paths = Complete(dm.FilePath, nil, "/path5/", 0)
+	if len(paths) != 1 || paths[0] != "/path5/different" {
+		t.Fatalf("expected prefix completion of /path5/different, got %v", paths)
+	}
+
+	if paths := Complete(dm.FilePath, nil, "", 2); len(paths) != 2 {
+		t.Fatalf("expected the limit to apply, got %v", paths)
+	}
+}
+
+func TestQueryList(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+	dm.QueryDummyData()
+
+	ranked, err := QueryList([]string{"test"}, dm.FilePath, Filter{}, 0)
+	if err != nil {
+		t.Fatalf("QueryList: %v", err)
+	}
+	if len(ranked) != 4 || ranked[0].Path.Path != "/path1/test" {
+		t.Fatalf("unexpected ranking: %v", ranked)
+	}
+
+	all, err := QueryList(nil, dm.FilePath, Filter{}, 3)
+	if err != nil {
+		t.Fatalf("QueryList: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+
+	reloaded, err := db.NewDirectoryManagerWithPath(dm.FilePath)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	first, _ := reloaded.Get("/path1/test")
+	if first.Score != 4 {
+		t.Fatalf("listing must not record a visit, score is %v", first.Score)
+	}
+}
@@ -32,6 +32,27 @@ func SetConfig() {
 		}
 	}
 
+	// increments decide how much each kind of visit adds to a score
+	setIncrementConfig()
+
+	// resolve symlinks decides whether stored paths have their symlinks resolved
+	val = os.Getenv("GOZELLE_RESOLVE_SYMLINKS")
+	if val == "" {
+		os.Setenv("GOZELLE_RESOLVE_SYMLINKS", "false")
+	} else if val != "false" && val != "true" {
+		fmt.Println("GOZELLE_RESOLVE_SYMLINKS must be true or false")
+		os.Setenv("GOZELLE_RESOLVE_SYMLINKS", "false")
+	}
+
+	// ambiguity ratio decides when gz prompts between close matches (0 disables)
+	val = os.Getenv("GOZELLE_AMBIGUITY_RATIO")
+	if val == "" {
+		os.Setenv("GOZELLE_AMBIGUITY_RATIO", "0")
+	} else if ratio, err := strconv.ParseFloat(val, 64); err != nil || ratio < 0 || ratio > 1 {
+		fmt.Fprintln(os.Stderr, "GOZELLE_AMBIGUITY_RATIO must be a number between 0 and 1")
+		os.Setenv("GOZELLE_AMBIGUITY_RATIO", "0")
+	}
+
 	var filePath string
 	// data_dir decides where the data is stored
 	val = os.Getenv("GOZELLE_DATA_DIR")
@@ -47,11 +68,11 @@ func SetConfig() {
 		filePath := filepath.Join(dataDir, "gozelle", "db.gob")
 		os.Setenv("GOZELLE_DATA_DIR", filePath)
 	} else if val != filePath {
-		// check if the directory exists
-		if _, err := os.Stat(val); os.IsNotExist(err) {
-			// create the directory
-			fmt.Println("Creating data directory:", val)
-			err := os.MkdirAll(val, os.ModePerm)
+		// GOZELLE_DATA_DIR names the data file, make sure its directory exists
+		dir := filepath.Dir(val)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			fmt.Println("Creating data directory:", dir)
+			err := os.MkdirAll(dir, os.ModePerm)
 			if err != nil {
 				fmt.Println("Error creating directory:", err)
 				os.Exit(1)
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetConfigDataFile(t *testing.T) {
+	// SetConfig fills in defaults, restore them afterwards
+	for _, name := range []string{"GOZELLE_ECHO", "GOZELLE_MINIMUM_WEIGHT", "GOZELLE_AMBIGUITY_RATIO"} {
+		t.Setenv(name, os.Getenv(name))
+	}
+	dataPath := filepath.Join(t.TempDir(), "data", "db.gob")
+	t.Setenv("GOZELLE_DATA_DIR", dataPath)
+
+	SetConfig()
+	if info, err := os.Stat(filepath.Dir(dataPath)); err != nil || !info.IsDir() {
+		t.Fatalf("expected the directory of the data file to be created, got %v", err)
+	}
+	if _, err := os.Stat(dataPath); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be created at the data file path itself, got %v", err)
+	}
+}
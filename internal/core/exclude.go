@@ -0,0 +1,219 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// ExcludeEnv lists glob patterns of directories that are never recorded,
+// separated like PATH. When it is unset the home directory is excluded.
+const ExcludeEnv = "GOZELLE_EXCLUDE"
+
+// ConfigDir returns $XDG_CONFIG_HOME/gozelle, or ~/.config/gozelle, and ""
+// when neither can be determined.
+func ConfigDir() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "gozelle")
+}
+
+// ExcludeFile returns the file holding the exclusion patterns added with
+// gozelle ignore, one per line.
+func ExcludeFile() string {
+	dir := ConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "exclude")
+}
+
+// ExcludePatterns returns the active exclusion patterns: those of
+// GOZELLE_EXCLUDE, or the home directory when it is unset, followed by the
+// ones in the exclude file. ~ and environment variables are expanded.
+func ExcludePatterns() ([]string, error) {
+	var patterns []string
+	if val, ok := os.LookupEnv(ExcludeEnv); ok {
+		for _, p := range filepath.SplitList(val) {
+			if p != "" {
+				patterns = append(patterns, ExpandPattern(p))
+			}
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		patterns = append(patterns, home)
+	}
+
+	file := ExcludeFile()
+	if file == "" {
+		return patterns, nil
+	}
+	fromFile, err := readPatterns(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range fromFile {
+		patterns = append(patterns, ExpandPattern(p))
+	}
+	return patterns, nil
+}
+
+// readPatterns returns the lines of the file at path, skipping blank lines and
+// # comments. A missing file has no patterns.
+func readPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// ExpandPattern expands a leading ~ and environment variables in pattern.
+func ExpandPattern(pattern string) string {
+	if pattern == "~" || strings.HasPrefix(pattern, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = home + pattern[1:]
+		}
+	}
+	return os.ExpandEnv(pattern)
+}
+
+// ValidatePattern reports a malformed glob pattern.
+func ValidatePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// MatchExclude reports whether the directory dir is matched by pattern.
+// An absolute pattern is matched against the whole path, with * not crossing
+// a /, and a trailing /** also matches everything below. A relative pattern
+// such as node_modules or .git/* matches any run of path components, so it
+// also excludes the directories below the match.
+func MatchExclude(pattern, dir string) bool {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	pattern = filepath.ToSlash(pattern)
+
+	if strings.HasPrefix(pattern, "/") {
+		if base, ok := strings.CutSuffix(pattern, "/**"); ok {
+			for p := dir; ; p = path.Dir(p) {
+				if ok, _ := path.Match(base, p); ok {
+					return true
+				}
+				if p == "/" || p == "." {
+					return false
+				}
+			}
+		}
+		ok, _ := path.Match(path.Clean(pattern), dir)
+		return ok
+	}
+
+	pattern = strings.Trim(pattern, "/")
+	n := strings.Count(pattern, "/") + 1
+	parts := strings.Split(strings.Trim(dir, "/"), "/")
+	for i := 0; i+n <= len(parts); i++ {
+		if ok, _ := path.Match(pattern, strings.Join(parts[i:i+n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded returns the first of patterns matching dir, or "" if none does.
+func Excluded(dir string, patterns []string) string {
+	for _, p := range patterns {
+		if MatchExclude(p, dir) {
+			return p
+		}
+	}
+	return ""
+}
+
+// AddExcludePattern appends pattern to the exclude file, creating it if needed.
+// It reports false if the file already had the pattern.
+func AddExcludePattern(pattern string) (bool, error) {
+	if err := ValidatePattern(pattern); err != nil {
+		return false, err
+	}
+	file := ExcludeFile()
+	if file == "" {
+		return false, fmt.Errorf("could not determine the config directory")
+	}
+	existing, err := readPatterns(file)
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(existing, pattern) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if _, err := fmt.Fprintln(f, pattern); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// PurgeExcluded removes the entries matched by any of patterns from the store
+// at dataPath and returns their paths.
+func PurgeExcluded(dataPath string, patterns []string) ([]string, error) {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+
+	var removed []string
+	kept := database.Entries[:0]
+	for _, dir := range database.Entries {
+		if Excluded(dir.Path, patterns) != "" {
+			if !slices.Contains(removed, dir.Path) {
+				removed = append(removed, dir.Path)
+			}
+		} else {
+			kept = append(kept, dir)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	database.Entries = kept
+	database.Dirty = true
+	return removed, database.Save()
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestMatchExclude(t *testing.T) {
+	cases := []struct {
+		pattern, dir string
+		want         bool
+	}{
+		{"/home/me", "/home/me", true},
+		{"/home/me", "/home/me/src", false},
+		{"/tmp/*", "/tmp/xyz", true},
+		{"/tmp/*", "/tmp/xyz/src", false},
+		{"/tmp/*", "/tmp", false},
+		{"/tmp/**", "/tmp", true},
+		{"/tmp/**", "/tmp/xyz/src", true},
+		{"/tmp/**", "/tmpfs", false},
+		{"node_modules", "/src/app/node_modules", true},
+		{"node_modules", "/src/app/node_modules/pkg", true},
+		{"node_modules", "/src/node_modules_old", false},
+		{".git/refs", "/src/app/.git/refs/heads", true},
+		{".git/refs", "/src/app/.git", false},
+		{".git/*", "/src/app/.git/hooks", true},
+		{"/home/me/", "/home/me", true},
+	}
+	for _, c := range cases {
+		if got := MatchExclude(c.pattern, c.dir); got != c.want {
+			t.Errorf("MatchExclude(%q, %q) = %v, want %v", c.pattern, c.dir, got, c.want)
+		}
+	}
+}
+
+func TestExcludePatterns(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv(ExcludeEnv, "")
+	os.Unsetenv(ExcludeEnv)
+
+	patterns, err := ExcludePatterns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(patterns, []string{home}) {
+		t.Fatalf("expected only the home directory by default, got %v", patterns)
+	}
+
+	for _, p := range []string{"node_modules", "~/Downloads", "node_modules"} {
+		if _, err := AddExcludePattern(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := AddExcludePattern("[oops"); err == nil {
+		t.Fatal("expected an invalid pattern to be rejected")
+	}
+
+	t.Setenv(ExcludeEnv, "/tmp/*"+string(os.PathListSeparator)+"$HOME/private")
+	patterns, err = ExcludePatterns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/tmp/*", filepath.Join(home, "private"), "node_modules", filepath.Join(home, "Downloads")}
+	if !slices.Equal(patterns, want) {
+		t.Fatalf("expected %v, got %v", want, patterns)
+	}
+
+	if err := Add(filepath.Join(home, "private"), AddOptions{Force: true}); err == nil {
+		t.Fatal("expected Add to refuse an excluded directory")
+	}
+}
+
+func TestPurgeExcluded(t *testing.T) {
+	dm, err := db.NewDirectoryManagerWithPath(filepath.Join(t.TempDir(), "db.gob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/src/app", "/src/app/node_modules/pkg", "/tmp/xyz", "/tmp/xyz"} {
+		dm.Add(p)
+	}
+	if err := dm.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := PurgeExcluded(dm.FilePath, []string{"node_modules", "/tmp/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(removed, []string{"/src/app/node_modules/pkg", "/tmp/xyz"}) {
+		t.Fatalf("unexpected removed entries %v", removed)
+	}
+
+	reloaded, err := db.NewDirectoryManagerWithPath(dm.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Entries) != 1 || reloaded.Entries[0].Path != "/src/app" {
+		t.Fatalf("expected only /src/app to remain, got %v", reloaded.Entries)
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// Filter restricts which entries are considered by query, list and interactive.
+// A zero Filter allows every entry.
+type Filter struct {
+	Since  time.Time // only entries visited at or after this time
+	Before time.Time // only entries visited before this time
+	Tag    string    // only entries carrying this tag
+}
+
+// Allows reports whether dir passes the filter.
+func (f Filter) Allows(dir *db.Directory) bool {
+	lastVisit := time.Unix(int64(dir.LastVisit), 0)
+	if !f.Since.IsZero() && lastVisit.Before(f.Since) {
+		return false
+	}
+	if !f.Before.IsZero() && !lastVisit.Before(f.Before) {
+		return false
+	}
+	if f.Tag != "" && !dir.HasTag(f.Tag) {
+		return false
+	}
+	return true
+}
+
+// filterEntries returns the entries of dirs allowed by filter.
+func filterEntries(dirs []*db.Directory, filter Filter) []*db.Directory {
+	var allowed []*db.Directory
+	for _, dir := range dirs {
+		if filter.Allows(dir) {
+			allowed = append(allowed, dir)
+		}
+	}
+	return allowed
+}
+
+// Args returns the --since, --before and --tag flags that reproduce the filter.
+func (f Filter) Args() []string {
+	var args []string
+	if !f.Since.IsZero() {
+		args = append(args, "--since", time.Since(f.Since).Round(time.Second).String())
+	}
+	if !f.Before.IsZero() {
+		args = append(args, "--before", f.Before.Format(time.RFC3339))
+	}
+	if f.Tag != "" {
+		args = append(args, "--tag", f.Tag)
+	}
+	return args
+}
+
+// ParseDuration parses durations like 90m, 2h, 3d or 1w.
+// Days and weeks are accepted on top of the units understood by time.ParseDuration.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("duration must not be negative: %q", s)
+		}
+		return d, nil
+	}
+
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseDate parses an absolute date (2006-01-02, optionally with a time, or RFC 3339)
+// in local time. A duration such as 3d is also accepted and means that long ago.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD[ HH:MM[:SS]], RFC 3339 or a duration like 3d)", s)
+}
+
+// NewTimeFilter builds a Filter from the --since and --before flag values.
+// Empty values leave the corresponding bound open.
+func NewTimeFilter(since, before string) (Filter, error) {
+	var f Filter
+	now := time.Now()
+	if since != "" {
+		d, err := ParseDuration(since)
+		if err != nil {
+			return f, fmt.Errorf("--since: %w", err)
+		}
+		f.Since = now.Add(-d)
+	}
+	if before != "" {
+		t, err := ParseDate(before, now)
+		if err != nil {
+			return f, fmt.Errorf("--before: %w", err)
+		}
+		f.Before = t
+	}
+	return f, nil
+}
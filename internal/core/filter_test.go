@@ -0,0 +1,106 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"2h":   2 * time.Hour,
+		"90m":  90 * time.Minute,
+		"3d":   3 * 24 * time.Hour,
+		"1w":   7 * 24 * time.Hour,
+		"1.5d": 36 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := ParseDuration(in)
+		if err != nil {
+			t.Fatalf("ParseDuration(%q) returned error: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("ParseDuration(%q) = %v, expected %v", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "d", "xw", "-3d", "3y"} {
+		if _, err := ParseDuration(in); err == nil {
+			t.Fatalf("expected ParseDuration(%q) to fail", in)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.Local)
+
+	got, err := ParseDate("2025-06-01", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("unexpected date %v", got)
+	}
+
+	got, err = ParseDate("2d", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Equal(now.Add(-48 * time.Hour)) {
+		t.Fatalf("unexpected date %v", got)
+	}
+
+	if _, err := ParseDate("yesterday", now); err == nil {
+		t.Fatal("expected error for unparseable date")
+	}
+}
+
+func TestFilterAllows(t *testing.T) {
+	now := time.Now()
+	recent := &db.Directory{Path: "/recent", LastVisit: db.Age(now.Add(-time.Hour).Unix()), Score: 1}
+	old := &db.Directory{Path: "/old", LastVisit: db.Age(now.Add(-72 * time.Hour).Unix()), Score: 1}
+
+	if !(Filter{}).Allows(old) {
+		t.Fatal("expected zero filter to allow every entry")
+	}
+
+	since := Filter{Since: now.Add(-24 * time.Hour)}
+	if !since.Allows(recent) || since.Allows(old) {
+		t.Fatal("expected since filter to keep only the recent entry")
+	}
+
+	before := Filter{Before: now.Add(-24 * time.Hour)}
+	if before.Allows(recent) || !before.Allows(old) {
+		t.Fatal("expected before filter to keep only the old entry")
+	}
+}
+
+func TestQueryTopFiltered(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.QueryDummyData()
+	// /path1/test has the highest score but was last visited a week ago
+	dm.Entries[0].LastVisit = db.Age(time.Now().Add(-7 * 24 * time.Hour).Unix())
+	dm.Dirty = true
+	dm.Save()
+
+	filter := Filter{Since: time.Now().Add(-24 * time.Hour)}
+	match := QueryTopFiltered([]string{"test"}, dm.FilePath, filter)
+	if match.Path == nil {
+		t.Fatal("expected a match, got nil")
+	}
+	if match.Path.Path == "/path1/test" {
+		t.Fatal("expected /path1/test to be excluded by the since filter")
+	}
+
+	filter = Filter{Before: time.Now().Add(-24 * time.Hour)}
+	match = QueryTopFiltered([]string{"test"}, dm.FilePath, filter)
+	if match.Path == nil || match.Path.Path != "/path1/test" {
+		t.Fatalf("expected /path1/test, got %v", match.Path)
+	}
+}
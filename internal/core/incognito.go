@@ -0,0 +1,14 @@
+package core
+
+import "os"
+
+// IncognitoEnv is set in shell sessions where `gozelle incognito on` paused
+// tracking. The shell functions installed by init export it.
+const IncognitoEnv = "GOZELLE_INCOGNITO"
+
+// Incognito reports whether visits should go unrecorded in this session, that
+// is whether IncognitoEnv is non-empty. The shell hooks use the same rule, so
+// any value, even 0, pauses both the hooks and the jumps.
+func Incognito() bool {
+	return os.Getenv(IncognitoEnv) != ""
+}
@@ -1,21 +1,218 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/atliod/gozelle/internal/db"
 )
 
-func List() error {
+// ListEntry is a directory as exposed by `gozelle list`.
+// The json and ndjson formats encode it with the field names given in the tags,
+// and --template is executed once per entry with it as dot, e.g.
+//
+//	gozelle list --template '{{.Path}} {{printf "%.1f" .Frecency}} {{.Ago}}'
+//
+// The table and tsv formats escape control characters in paths,
+// use json or --print0 when exact paths are needed.
+type ListEntry struct {
+	Path      string    `json:"path"`       // the directory path
+	Score     float64   `json:"score"`      // the raw visit score
+	Frecency  float64   `json:"frecency"`   // score weighted by recency, as used for ranking
+	LastVisit time.Time `json:"last_visit"` // when the directory was last visited
+	Pinned    bool      `json:"pinned"`     // whether the directory is pinned
+
+	FirstSeen    time.Time      `json:"first_seen"`         // when the directory was first recorded
+	Visits       int            `json:"visits"`             // the number of recorded visits
+	VisitsByKind map[string]int `json:"visits_by_kind"`     // the visits by kind: cd, jump, interactive and manual
+	RecentVisits []time.Time    `json:"recent_visits"`      // the latest visits, oldest first
+	LastJump     time.Time      `json:"last_jump,omitzero"` // when gz or gi last jumped to it
+	Source       string         `json:"source"`             // how it was first recorded: hook, import, manual or unknown
+	Tags         []string       `json:"tags"`               // the tags it carries
+	Mark         string         `json:"mark"`               // its bookmark name, "" if none
+}
+
+// newListEntry describes dir with its frecency as of now.
+func newListEntry(dir *db.Directory, now time.Time) ListEntry {
+	e := ListEntry{
+		Path:         dir.Path,
+		Score:        float64(dir.Score),
+		Frecency:     WeighFrecencyAt(dir, now),
+		LastVisit:    time.Unix(int64(dir.LastVisit), 0),
+		Pinned:       dir.Pinned,
+		FirstSeen:    time.Unix(int64(dir.FirstSeen), 0),
+		Visits:       dir.Visits.Total(),
+		VisitsByKind: map[string]int{},
+		RecentVisits: make([]time.Time, len(dir.Recent)),
+		Source:       dir.Source.String(),
+		Tags:         append([]string{}, dir.Tags...),
+		Mark:         dir.Mark,
+	}
+	for i, kind := range db.VisitKinds {
+		e.VisitsByKind[kind] = dir.Visits.Of(db.VisitKind(i))
+	}
+	for i, visit := range dir.Recent {
+		e.RecentVisits[i] = time.Unix(int64(visit), 0)
+	}
+	if dir.LastJump != 0 {
+		e.LastJump = time.Unix(int64(dir.LastJump), 0)
+	}
+	return e
+}
+
+// Ago returns the last visit relative to now in a human friendly form.
+func (e ListEntry) Ago() string {
+	return HumanizeSince(e.LastVisit, time.Now())
+}
+
+// ListOptions controls which entries List prints and how.
+type ListOptions struct {
+	Keywords []string // only entries matching these keywords, if any
+	Filter   Filter
+	Sort     string // frecency, score, recent, visits or path
+	Reverse  bool
+	Limit    int    // maximum number of entries, 0 for all
+	Format   string // table, json, ndjson or tsv
+	Template string // text/template executed per entry, overrides Format
+	Print0   bool   // print only the paths, each terminated by NUL, overrides Format
+}
+
+var (
+	ListSorts   = []string{"frecency", "score", "recent", "visits", "path"}
+	ListFormats = []string{"table", "json", "ndjson", "tsv"}
+)
+
+// List prints the directories in the store according to opts.
+func List(opts ListOptions) error {
 	database, err := db.NewDirectoryManager()
 	if err != nil {
 		panic(err)
 	}
 
-	// Print the list of directories
-	for _, dir := range database.Entries {
-		fmt.Println("Path: ", dir.Path, "|Frequency Score:", dir.Score)
-		fmt.Println("-------------------------------------------------------------------------------")
+	entries, err := ListEntries(database.Entries, opts)
+	if err != nil {
+		return err
+	}
+	return WriteList(os.Stdout, entries, opts)
+}
+
+// ListEntries filters, sorts and limits dirs according to opts.
+func ListEntries(dirs []*db.Directory, opts ListOptions) ([]ListEntry, error) {
+	now := time.Now()
+	entries := make([]ListEntry, 0, len(dirs))
+	for _, dir := range dirs {
+		if !opts.Filter.Allows(dir) {
+			continue
+		}
+		if len(opts.Keywords) > 0 && !MatchByKeywords(dir.Path, opts.Keywords) {
+			continue
+		}
+		entries = append(entries, newListEntry(dir, now))
+	}
+
+	var less func(a, b ListEntry) bool
+	switch opts.Sort {
+	case "", "frecency":
+		less = func(a, b ListEntry) bool { return a.Frecency > b.Frecency }
+	case "score":
+		less = func(a, b ListEntry) bool { return a.Score > b.Score }
+	case "recent":
+		less = func(a, b ListEntry) bool { return a.LastVisit.After(b.LastVisit) }
+	case "visits":
+		less = func(a, b ListEntry) bool { return a.Visits > b.Visits }
+	case "path":
+		less = func(a, b ListEntry) bool { return a.Path < b.Path }
+	default:
+		return nil, fmt.Errorf("unknown sort %q (expected one of %v)", opts.Sort, ListSorts)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if opts.Reverse {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+	return entries, nil
+}
+
+// WriteList renders entries to w in the format selected by opts.
+func WriteList(w io.Writer, entries []ListEntry, opts ListOptions) error {
+	if opts.Print0 {
+		for _, e := range entries {
+			fmt.Fprint(w, e.Path, "\x00")
+		}
+		return nil
+	}
+
+	if opts.Template != "" {
+		tmpl, err := template.New("list").Parse(opts.Template)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		for _, e := range entries {
+			if err := tmpl.Execute(w, e); err != nil {
+				return fmt.Errorf("executing template: %w", err)
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	switch opts.Format {
+	case "", "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FRECENCY\tSCORE\tLAST VISIT\tPATH")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%.2f\t%.2f\t%s\t%s\n", e.Frecency, e.Score, e.Ago(), EscapePath(e.Path))
+		}
+		return tw.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "tsv":
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%g\t%g\t%d\n", EscapePath(e.Path), e.Score, e.Frecency, e.LastVisit.Unix())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (expected one of %v)", opts.Format, ListFormats)
+	}
+}
+
+// HumanizeSince describes how long before now t was, e.g. "5m ago" or "3d ago".
+// Anything older than a few weeks is shown as a date.
+func HumanizeSince(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 5*7*24*time.Hour:
+		return fmt.Sprintf("%dw ago", int(d.Hours()/(24*7)))
+	default:
+		return t.Format("2006-01-02")
 	}
-	return nil
 }
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func listTestDirs() []*db.Directory {
+	now := time.Now()
+	return []*db.Directory{
+		{Path: "/b/api", Score: 2, LastVisit: db.Age(now.Add(-2 * time.Hour).Unix())},
+		{Path: "/a/api", Score: 5, LastVisit: db.Age(now.Add(-48 * time.Hour).Unix())},
+		{Path: "/c/web", Score: 1, LastVisit: db.Age(now.Unix())},
+	}
+}
+
+func TestListEntriesSortAndLimit(t *testing.T) {
+	entries, err := ListEntries(listTestDirs(), ListOptions{Sort: "score"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries[0].Path != "/a/api" || entries[2].Path != "/c/web" {
+		t.Fatalf("unexpected score order: %v", entries)
+	}
+
+	entries, _ = ListEntries(listTestDirs(), ListOptions{Sort: "recent", Limit: 2})
+	if len(entries) != 2 || entries[0].Path != "/c/web" || entries[1].Path != "/b/api" {
+		t.Fatalf("unexpected recent order: %v", entries)
+	}
+
+	entries, _ = ListEntries(listTestDirs(), ListOptions{Sort: "path", Reverse: true})
+	if entries[0].Path != "/c/web" || entries[2].Path != "/a/api" {
+		t.Fatalf("unexpected reversed path order: %v", entries)
+	}
+
+	entries, _ = ListEntries(listTestDirs(), ListOptions{Keywords: []string{"api"}})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries matching api, got %d", len(entries))
+	}
+
+	if _, err := ListEntries(listTestDirs(), ListOptions{Sort: "size"}); err == nil {
+		t.Fatal("expected error for unknown sort")
+	}
+}
+
+func TestWriteListFormats(t *testing.T) {
+	entries, _ := ListEntries(listTestDirs(), ListOptions{Sort: "path"})
+
+	var buf bytes.Buffer
+	if err := WriteList(&buf, entries, ListOptions{Format: "json"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json output: %v", err)
+	}
+	if len(decoded) != 3 || decoded[0]["path"] != "/a/api" {
+		t.Fatalf("unexpected json output: %s", buf.String())
+	}
+
+	buf.Reset()
+	WriteList(&buf, entries, ListOptions{Format: "ndjson"})
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 3 {
+		t.Fatalf("expected 3 ndjson lines, got %d", len(lines))
+	}
+
+	buf.Reset()
+	WriteList(&buf, entries, ListOptions{Format: "tsv"})
+	if !strings.HasPrefix(buf.String(), "/a/api\t5\t") {
+		t.Fatalf("unexpected tsv output: %q", buf.String())
+	}
+
+	buf.Reset()
+	WriteList(&buf, entries, ListOptions{Template: "{{.Path}} {{.Ago}}"})
+	if !strings.Contains(buf.String(), "/c/web just now\n") {
+		t.Fatalf("unexpected template output: %q", buf.String())
+	}
+
+	buf.Reset()
+	WriteList(&buf, entries, ListOptions{Print0: true, Format: "json"})
+	if buf.String() != "/a/api\x00/b/api\x00/c/web\x00" {
+		t.Fatalf("unexpected print0 output: %q", buf.String())
+	}
+
+	if err := WriteList(&buf, entries, ListOptions{Format: "xml"}); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestHumanizeSince(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[time.Duration]string{
+		10 * time.Second:     "just now",
+		5 * time.Minute:      "5m ago",
+		3 * time.Hour:        "3h ago",
+		50 * time.Hour:       "2d ago",
+		15 * 24 * time.Hour:  "2w ago",
+		100 * 24 * time.Hour: "2025-03-02",
+	}
+	for d, want := range cases {
+		if got := HumanizeSince(now.Add(-d), now); got != want {
+			t.Fatalf("HumanizeSince(%v) = %q, expected %q", d, got, want)
+		}
+	}
+}
+
+func TestListEntryMetadata(t *testing.T) {
+	dir := db.NewDirectory("/src/app")
+	dir.Score = 0
+	dir.Source = db.SourceOf(db.VisitCd)
+	dir.Visit(db.VisitCd, 1)
+	dir.Visit(db.VisitJump, 2)
+
+	entries, err := ListEntries(append(listTestDirs(), dir), ListOptions{Sort: "visits", Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := entries[0]
+	if e.Path != "/src/app" || e.Visits != 2 || e.VisitsByKind["jump"] != 1 || e.VisitsByKind["cd"] != 1 {
+		t.Fatalf("unexpected visits in %+v", e)
+	}
+	if e.Source != "hook" || e.LastJump.IsZero() || len(e.RecentVisits) != 2 || e.FirstSeen.IsZero() {
+		t.Fatalf("unexpected metadata in %+v", e)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteList(&buf, entries, ListOptions{Format: "json"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, field := range []string{`"first_seen"`, `"visits_by_kind"`, `"recent_visits"`, `"last_jump"`, `"source": "hook"`} {
+		if !strings.Contains(buf.String(), field) {
+			t.Fatalf("expected %s in json output:\n%s", field, buf.String())
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+const (
+	ManageDelete  = "delete"
+	ManagePin     = "pin"
+	ManageRescore = "rescore"
+)
+
+// ManageActions lists the actions available in interactive --manage mode.
+var ManageActions = []string{ManageDelete, ManagePin, ManageRescore}
+
+// manageBinding ties a manage action to its key (pressed with Alt) and a description.
+type manageBinding struct {
+	key    rune
+	action string
+	label  string
+}
+
+var manageBindings = []manageBinding{
+	{'d', ManageDelete, "delete"},
+	{'p', ManagePin, "pin/unpin"},
+	{'r', ManageRescore, "reset score"},
+}
+
+// manageHeader describes the manage bindings for the picker header.
+func manageHeader() string {
+	header := ""
+	for i, b := range manageBindings {
+		if i > 0 {
+			header += "  "
+		}
+		header += fmt.Sprintf("alt-%c: %s", b.key, b.label)
+	}
+	return header
+}
+
+// ApplyManageAction applies action to each of paths in the store at dataPath and saves it.
+// delete removes the entries, pin toggles their pin and rescore resets their score
+// to that of a newly added directory.
+func ApplyManageAction(dataPath, action string, paths []string) error {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+
+	for _, path := range paths {
+		switch action {
+		case ManageDelete:
+			err = database.Remove(path)
+		case ManagePin:
+			err = database.TogglePin(path)
+		case ManageRescore:
+			err = database.SetScore(path, db.DefaultScore)
+		default:
+			return fmt.Errorf("unknown action %q (expected one of %v)", action, ManageActions)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return database.Save()
+}
+
+// loadCandidates returns the entries of the store at dataPath allowed by filter.
+func loadCandidates(dataPath string, filter Filter) ([]*db.Directory, error) {
+	dm, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+
+	var candidates []*db.Directory
+	for _, dir := range dm.Entries {
+		if filter.Allows(dir) {
+			candidates = append(candidates, dir)
+		}
+	}
+	return candidates, nil
+}
+
+// WriteManageLines writes the candidate lines for an external picker, NUL-terminated.
+// It is what the picker's reload binding runs after each manage action.
+func WriteManageLines(w io.Writer, dataPath, pickerName string, filter Filter) error {
+	p, ok := externalPickers[pickerName]
+	if !ok || !p.columns {
+		return fmt.Errorf("picker %q does not support --manage", pickerName)
+	}
+	candidates, err := loadCandidates(dataPath, filter)
+	if err != nil {
+		return err
+	}
+	for _, line := range p.Lines(RankByFrecency(candidates)) {
+		fmt.Fprint(w, line, "\x00")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestApplyManageAction(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.QueryDummyData()
+
+	if err := ApplyManageAction(dm.FilePath, ManageDelete, []string{"/path2/test", "/path3/test"}); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := ApplyManageAction(dm.FilePath, ManagePin, []string{"/path4/test"}); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := ApplyManageAction(dm.FilePath, ManageRescore, []string{"/path1/test"}); err != nil {
+		t.Fatalf("failed to rescore: %v", err)
+	}
+
+	reloaded, err := db.NewDirectoryManagerWithPath(dm.FilePath)
+	if err != nil {
+		t.Fatalf("failed to reload store: %v", err)
+	}
+	if len(reloaded.Entries) != 3 {
+		t.Fatalf("expected 3 entries after deleting 2, got %d", len(reloaded.Entries))
+	}
+	if entry, _ := reloaded.Get("/path4/test"); !entry.Pinned {
+		t.Fatal("expected /path4/test to be pinned")
+	}
+	if entry, _ := reloaded.Get("/path1/test"); entry.Score != db.DefaultScore {
+		t.Fatalf("expected /path1/test to be rescored to %f, got %f", db.DefaultScore, entry.Score)
+	}
+
+	if err := ApplyManageAction(dm.FilePath, "explode", []string{"/path1/test"}); err == nil {
+		t.Fatal("expected error for unknown action")
+	}
+}
+
+func TestWriteManageLines(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.QueryDummyData()
+
+	var buf bytes.Buffer
+	if err := WriteManageLines(&buf, dm.FilePath, PickerFzf, Filter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\x00"), "\x00")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %d", len(lines))
+	}
+	if path, _ := externalPickers[PickerFzf].Selection(lines[0]); path != "/path1/test" {
+		t.Fatalf("expected the most frecent entry first, got %q", path)
+	}
+
+	if err := WriteManageLines(&buf, dm.FilePath, PickerFzy, Filter{}); err == nil {
+		t.Fatal("expected error for a picker without column support")
+	}
+}
+
+func TestManageArgs(t *testing.T) {
+	args, err := externalPickers[PickerFzf].Args(PickOptions{Manage: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"--multi", "alt-d:execute-silent(", " manage delete -- {+5..})+clear-selection+reload(", "manage lines --picker fzf)"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected args to contain %q, got %q", want, joined)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// MarkPrefix marks a query naming a bookmark, as in gz :infra.
+const MarkPrefix = ":"
+
+// ValidateMark rejects bookmark names that could not be typed as :name queries.
+func ValidateMark(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid bookmark %q: use letters, digits, _, . and -", name)
+	}
+	return nil
+}
+
+// markQuery returns the bookmark name if keywords is a single :name query.
+func markQuery(keywords []string) (string, bool) {
+	if len(keywords) != 1 {
+		return "", false
+	}
+	return strings.CutPrefix(keywords[0], MarkPrefix)
+}
+
+// Bookmark is a bookmark name and the path it resolves to.
+type Bookmark struct {
+	Name string
+	Path string
+}
+
+// SetMark makes name a bookmark of path in the store at dataPath, moving it if
+// it named another directory. The path is added if it is not stored yet, see
+// storedOrAdded.
+func SetMark(dataPath, name, path string) error {
+	if err := ValidateMark(name); err != nil {
+		return err
+	}
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	dir, err := storedOrAdded(database, path)
+	if err != nil {
+		return err
+	}
+	if err := database.SetMark(dir.Path, name); err != nil {
+		return err
+	}
+	return database.Save()
+}
+
+// RemoveMark removes the bookmark name from the store at dataPath.
+func RemoveMark(dataPath, name string) error {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	if err := database.Unmark(strings.TrimPrefix(name, MarkPrefix)); err != nil {
+		return err
+	}
+	return database.Save()
+}
+
+// Marks returns the bookmarks in the store at dataPath, by name.
+func Marks(dataPath string) ([]Bookmark, error) {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	var marks []Bookmark
+	for _, dir := range database.Entries {
+		if dir.Mark != "" {
+			marks = append(marks, Bookmark{dir.Mark, dir.Path})
+		}
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i].Name < marks[j].Name })
+	return marks, nil
+}
+
+// CompleteMarks returns the bookmark names in the store at dataPath starting
+// with prefix.
+func CompleteMarks(dataPath, prefix string) []string {
+	marks, err := Marks(dataPath)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, mark := range marks {
+		if strings.HasPrefix(mark.Name, prefix) {
+			names = append(names, mark.Name)
+		}
+	}
+	return names
+}
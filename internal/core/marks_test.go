@@ -0,0 +1,59 @@
+package core
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestMarks(t *testing.T) {
+	dataPath, dirs := newTestTree(t, "platform/infra", "infra-busy")
+	infra, busy := dirs[0], dirs[1]
+	if err := RecordVisit(dataPath, db.VisitCd, busy, busy, busy); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetMark(dataPath, "infra", infra); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetMark(dataPath, "bad:name", infra); err == nil {
+		t.Fatal("expected an invalid bookmark name to be refused")
+	}
+	if err := SetMark(dataPath, "gone", filepath.Join(filepath.Dir(dataPath), "missing")); err == nil {
+		t.Fatal("expected marking a missing directory to fail")
+	}
+
+	marks, err := Marks(dataPath)
+	if err != nil || !slices.Equal(marks, []Bookmark{{"infra", infra}}) {
+		t.Fatalf("unexpected bookmarks %v, %v", marks, err)
+	}
+	if got := Complete(dataPath, nil, ":in", CompletionLimit); !slices.Equal(got, []string{":infra"}) {
+		t.Fatalf("unexpected : completions %v", got)
+	}
+
+	// the bookmark wins over the more frecent match for the keyword
+	best, err := QueryTopWithOptions([]string{":infra"}, dataPath, QueryOptions{})
+	if err != nil || best.Path == nil || best.Path.Path != infra {
+		t.Fatalf("expected :infra to resolve to %s, got %v, %v", infra, best.Path, err)
+	}
+	if best.Path.Visits.Jump != 1 {
+		t.Fatalf("expected the jump to be recorded, got %+v", best.Path.Visits)
+	}
+	if best := QueryTop([]string{":nope"}, dataPath); best.Path != nil {
+		t.Fatalf("expected no match for an unknown bookmark, got %s", best.Path.Path)
+	}
+
+	match := matchWithMarks([]*db.Directory{{Path: infra, Mark: "infra"}, {Path: busy}})
+	if !match(infra, []string{":inf"}) || match(busy, []string{":inf"}) || !match(busy, []string{"busy"}) {
+		t.Fatal("expected the picker to match bookmarks by :name")
+	}
+
+	if err := RemoveMark(dataPath, ":infra"); err != nil {
+		t.Fatal(err)
+	}
+	if marks, _ := Marks(dataPath); len(marks) != 0 {
+		t.Fatalf("expected no bookmarks left, got %v", marks)
+	}
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"path/filepath"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func init() {
+	db.Migrations[0] = canonicalizeEntries
+}
+
+// canonicalizeEntries rewrites the paths of a store saved before paths were
+// canonicalized on add, merging the entries that turn out to be the same
+// directory. Relative paths are left alone, as the directory they were relative
+// to is unknown. It upgrades stores from schema version 0.
+func canonicalizeEntries(dm *db.DirectoryManager) error {
+	resolve := ResolveSymlinks()
+	for _, dir := range dm.Entries {
+		if !filepath.IsAbs(dir.Path) {
+			continue
+		}
+		if canonical, err := CanonicalPath(dir.Path, resolve); err == nil {
+			dir.Path = canonical
+		}
+	}
+	return dm.Dedup()
+}
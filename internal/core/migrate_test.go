@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestCanonicalizeLegacyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.gob")
+	legacy := []*db.Directory{
+		{Path: "/src/app/", Score: 1, LastVisit: 10},
+		{Path: "/src//app", Score: 2, LastVisit: 30, Pinned: true},
+		{Path: "/src/other", Score: 1, LastVisit: 20},
+		{Path: "relative", Score: 1, LastVisit: 20},
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dm, err := db.NewDirectoryManagerWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm.Version != db.SchemaVersion {
+		t.Fatalf("expected version %d, got %d", db.SchemaVersion, dm.Version)
+	}
+	if len(dm.Entries) != 3 {
+		t.Fatalf("expected 3 entries after merging, got %d", len(dm.Entries))
+	}
+	app, err := dm.Get("/src/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Score != 3 || app.LastVisit != 30 || !app.Pinned {
+		t.Fatalf("unexpected merged entry %+v", app)
+	}
+
+	// the migration is saved, so reloading finds the migrated store
+	reloaded, err := db.NewDirectoryManagerWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Version != db.SchemaVersion || len(reloaded.Entries) != 3 {
+		t.Fatalf("expected the migrated store on disk, got version %d with %d entries", reloaded.Version, len(reloaded.Entries))
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// ValidatePath rejects paths that cannot be passed around safely as text,
+// i.e. empty paths and paths containing control characters such as newlines.
+func ValidatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty path")
+	}
+	if strings.IndexFunc(path, unicode.IsControl) != -1 {
+		return fmt.Errorf("path contains control characters: %s", EscapePath(path))
+	}
+	return nil
+}
+
+// ResolveSymlinks reports whether stored paths have their symlinks resolved,
+// as set by GOZELLE_RESOLVE_SYMLINKS.
+func ResolveSymlinks() bool {
+	return os.Getenv("GOZELLE_RESOLVE_SYMLINKS") == "true"
+}
+
+// CanonicalPath returns path made absolute and cleaned, which is how paths are
+// stored. With resolveSymlinks the symlinks in it are resolved as well, if it exists.
+func CanonicalPath(path string, resolveSymlinks bool) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolveSymlinks {
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			return resolved, nil
+		}
+	}
+	return abs, nil
+}
+
+// storedPath returns the path under which database holds the entry for path as
+// given on the command line: its canonical form, or else path itself, which
+// matches the relative or uncleaned legacy entries the migration could not
+// canonicalize. If neither is stored the canonical form is returned.
+func storedPath(database *db.DirectoryManager, path string) string {
+	canonical, err := CanonicalPath(path, ResolveSymlinks())
+	if err != nil {
+		return path
+	}
+	if _, err := database.Get(canonical); err != nil {
+		if _, err := database.Get(path); err == nil {
+			return path
+		}
+	}
+	return canonical
+}
+
+// EscapePath makes path safe to print on a single line by quoting control characters.
+// Paths without control characters are returned unchanged.
+func EscapePath(path string) string {
+	if strings.IndexFunc(path, unicode.IsControl) == -1 {
+		return path
+	}
+	quoted := strconv.Quote(path)
+	return quoted[1 : len(quoted)-1]
+}
+
+// ShellQuote quotes s for use as a single word in a POSIX shell command line.
+func ShellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%_-+=:,./", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestValidatePath(t *testing.T) {
+	if err := ValidatePath("/home/user/my projects"); err != nil {
+		t.Fatalf("expected valid path, got %v", err)
+	}
+	for _, p := range []string{"", "/tmp/a\nb", "/tmp/a\x00b", "/tmp/\x1b[31mred"} {
+		if err := ValidatePath(p); err == nil {
+			t.Fatalf("expected ValidatePath(%q) to fail", p)
+		}
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	if got := EscapePath("/plain/path"); got != "/plain/path" {
+		t.Fatalf("expected path unchanged, got %q", got)
+	}
+	if got := EscapePath("/tmp/a\nb\tc"); got != `/tmp/a\nb\tc` {
+		t.Fatalf("unexpected escaped path %q", got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		"/usr/local/bin": "/usr/local/bin",
+		"/my dir":        "'/my dir'",
+		"it's":           `'it'\''s'`,
+		"":               "''",
+	}
+	for in, want := range cases {
+		if got := ShellQuote(in); got != want {
+			t.Fatalf("ShellQuote(%q) = %q, expected %q", in, got, want)
+		}
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dir, "real")
+	link := filepath.Join(dir, "link")
+	if err := os.Mkdir(real, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cases := []struct {
+		path    string
+		resolve bool
+		want    string
+	}{
+		{"./real", false, real},
+		{real + "/", false, real},
+		{dir + "//real/../real", false, real},
+		{"link", false, link},
+		{"link/", true, real},
+		{"missing/", true, filepath.Join(dir, "missing")},
+	}
+	for _, c := range cases {
+		got, err := CanonicalPath(c.path, c.resolve)
+		if err != nil || got != c.want {
+			t.Errorf("CanonicalPath(%q, %v) = %q, %v, want %q", c.path, c.resolve, got, err, c.want)
+		}
+	}
+}
+
+func TestRemoveLegacyEntry(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+	t.Setenv("GOZELLE_DATA_DIR", dm.FilePath)
+	t.Chdir(t.TempDir())
+
+	// entries the migration left alone, and so not in canonical form
+	dm.Add("legacy/relative")
+	dm.Add("/legacy//uncleaned")
+	dm.Save()
+
+	if got := storedPath(dm, "legacy/relative"); got != "legacy/relative" {
+		t.Fatalf("expected the raw legacy path, got %s", got)
+	}
+	for _, path := range []string{"legacy/relative", "/legacy//uncleaned"} {
+		if err := Remove(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reloaded, err := db.NewDirectoryManagerWithPath(dm.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Entries) != 0 {
+		t.Fatalf("expected the legacy entries to be removed, got %d left", len(reloaded.Entries))
+	}
+}
@@ -0,0 +1,435 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atliod/gozelle/internal/db"
+	"github.com/atliod/gozelle/internal/picker"
+)
+
+const (
+	PickerAuto    = "auto"
+	PickerFzf     = "fzf"
+	PickerSkim    = "sk"
+	PickerFzy     = "fzy"
+	PickerPeco    = "peco"
+	PickerBuiltin = "builtin"
+)
+
+// Pickers lists the accepted values for --picker and GOZELLE_PICKER.
+var Pickers = []string{PickerAuto, PickerFzf, PickerSkim, PickerFzy, PickerPeco, PickerBuiltin}
+
+// PickOptions is passed to a Picker for a single selection.
+type PickOptions struct {
+	Multi bool   // allow selecting several directories
+	Query string // initial query
+	// Manage enables the delete, pin and rescore bindings. The candidates are
+	// reloaded from the store at DataPath, restricted by Filter, after each action.
+	Manage   bool
+	DataPath string
+	Filter   Filter
+}
+
+// Picker lets the user choose among candidate directories.
+type Picker interface {
+	Pick(candidates []*db.Directory, opts PickOptions) ([]string, error)
+}
+
+// ResolvePicker turns a --picker value into the name of an available picker.
+// An empty name falls back to GOZELLE_PICKER. auto uses the first installed
+// external picker (fzf, sk, fzy, peco) and the built-in picker if there is none.
+func ResolvePicker(name string) (string, error) {
+	if name == "" {
+		name = os.Getenv("GOZELLE_PICKER")
+	}
+	switch name {
+	case "", PickerAuto:
+		for _, candidate := range []string{PickerFzf, PickerSkim, PickerFzy, PickerPeco} {
+			if _, err := exec.LookPath(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+		return PickerBuiltin, nil
+	case PickerBuiltin:
+		return PickerBuiltin, nil
+	}
+	if _, ok := externalPickers[name]; !ok {
+		return "", fmt.Errorf("unknown picker %q (expected one of %v)", name, Pickers)
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s is not installed, install it or use --picker builtin", name)
+	}
+	return name, nil
+}
+
+// NewPicker returns the picker with the given name, as returned by ResolvePicker.
+func NewPicker(name string) (Picker, error) {
+	if name == PickerBuiltin {
+		return builtinPicker{}, nil
+	}
+	p, ok := externalPickers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown picker %q (expected one of %v)", name, Pickers)
+	}
+	return p, nil
+}
+
+// externalPicker runs a fuzzy finder binary and feeds it the candidates on stdin.
+type externalPicker struct {
+	name  string // binary name, also used for GOZELLE_<NAME>_OPTS
+	read0 bool   // whether the picker exchanges NUL-delimited paths (--read0/--print0)
+	// columns pickers understand fzf's --delimiter, --with-nth, --nth and --preview,
+	// so candidates are shown with score and last-visit columns and a preview pane.
+	columns bool
+	args    func(opts PickOptions) []string
+}
+
+var externalPickers = map[string]externalPicker{
+	PickerFzf:  {name: PickerFzf, read0: true, columns: true, args: fzfArgs},
+	PickerSkim: {name: PickerSkim, read0: true, columns: true, args: fzfArgs},
+	PickerFzy: {
+		name: PickerFzy,
+		args: func(opts PickOptions) []string {
+			return []string{"--query", opts.Query}
+		},
+	},
+	PickerPeco: {
+		name: PickerPeco,
+		args: func(opts PickOptions) []string {
+			return []string{"--query", opts.Query}
+		},
+	},
+}
+
+// fzfArgs builds the arguments shared by fzf and skim. Each candidate line is
+// "index<TAB>frecency<TAB>last visit<TAB>:bookmark<TAB>path": the index is hidden
+// with --with-nth and only the bookmark and the path are matched with --nth, the
+// other columns are for display. --nth counts fields of the displayed line while
+// placeholders such as {5..} count fields of the original one.
+func fzfArgs(opts PickOptions) []string {
+	args := []string{
+		"--ansi", "--read0", "--print0",
+		"--query", opts.Query,
+		"--delimiter", "\t",
+		"--with-nth", "2..",
+		"--nth", "3..",
+		"--tiebreak", "index",
+		"--preview", previewCommand(),
+	}
+	if opts.Multi {
+		args = append(args, "--multi")
+	}
+	return args
+}
+
+// previewCommand lists the highlighted directory with the hidden preview subcommand.
+func previewCommand() string {
+	return ShellQuote(executable()) + " preview -- {5..}"
+}
+
+// executable returns the path of the running gozelle binary for picker callbacks.
+func executable() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "gozelle"
+	}
+	return exe
+}
+
+// OptsEnv returns the environment variable holding extra arguments for the picker,
+// e.g. GOZELLE_FZF_OPTS. GOZELLE_PICKER_OPTS applies to every external picker.
+func (p externalPicker) OptsEnv() string {
+	return "GOZELLE_" + strings.ToUpper(p.name) + "_OPTS"
+}
+
+// Args returns the full argument list, with user options last so they take precedence.
+func (p externalPicker) Args(opts PickOptions) ([]string, error) {
+	args := p.args(opts)
+	if opts.Manage {
+		args = append(args, p.manageArgs(opts)...)
+	}
+	for _, env := range []string{"GOZELLE_PICKER_OPTS", p.OptsEnv()} {
+		extra, err := SplitArgs(os.Getenv(env))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", env, err)
+		}
+		args = append(args, extra...)
+	}
+	return args, nil
+}
+
+// manageArgs binds the manage actions to hidden gozelle subcommands:
+// the action is applied to the selected entries (or the highlighted one)
+// and the list is reloaded from the store afterwards.
+func (p externalPicker) manageArgs(opts PickOptions) []string {
+	exe := ShellQuote(executable())
+	reload := exe + " manage lines --picker " + p.name
+	for _, arg := range opts.Filter.Args() {
+		reload += " " + ShellQuote(arg)
+	}
+
+	args := []string{"--multi", "--header", manageHeader()}
+	for _, b := range manageBindings {
+		args = append(args, "--bind", fmt.Sprintf("alt-%c:execute-silent(%s manage %s -- {+5..})+clear-selection+reload(%s)", b.key, exe, b.action, reload))
+	}
+	return args
+}
+
+// Lines renders the ranked candidates in the form the picker reads them.
+func (p externalPicker) Lines(ranked []ScoredMatch) []string {
+	now := time.Now()
+	lines := make([]string, 0, len(ranked))
+	for i, m := range ranked {
+		switch {
+		case p.columns:
+			lastVisit := time.Unix(int64(m.Path.LastVisit), 0)
+			lines = append(lines, fmt.Sprintf("%d\t%8.2f%s\t%-9s\t%s\t%s", i, m.Frecency, pinMark(m.Path), HumanizeSince(lastVisit, now), bookmarkLabel(m.Path), m.Path.Path))
+		case !p.read0 && strings.Contains(m.Path.Path, "\n"):
+			// newline-delimited pickers cannot represent paths with newlines
+		default:
+			lines = append(lines, m.Path.Path)
+		}
+	}
+	return lines
+}
+
+// Selection maps a line printed by the picker back to the path it stands for.
+// The path is taken from the line itself rather than looked up by index,
+// since the list may have been reloaded in --manage mode.
+func (p externalPicker) Selection(line string) (string, error) {
+	if !p.columns {
+		return line, nil
+	}
+	fields := strings.SplitN(line, "\t", 5)
+	if len(fields) != 5 || fields[4] == "" {
+		return "", fmt.Errorf("unexpected output from %s: %q", p.name, line)
+	}
+	return fields[4], nil
+}
+
+// bookmarkLabel shows the bookmark of dir as it is typed in a query, if any.
+func bookmarkLabel(dir *db.Directory) string {
+	if dir.Mark == "" {
+		return ""
+	}
+	return MarkPrefix + dir.Mark
+}
+
+// pinMark flags pinned directories next to their score.
+func pinMark(dir *db.Directory) string {
+	if dir.Pinned {
+		return " *"
+	}
+	return "  "
+}
+
+// Pick shows the candidates most frecent first and returns the selected paths.
+func (p externalPicker) Pick(candidates []*db.Directory, opts PickOptions) ([]string, error) {
+	args, err := p.Args(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sep := "\n"
+	if p.read0 {
+		sep = "\x00"
+	}
+	ranked := RankByFrecency(candidates)
+
+	cmd := exec.Command(p.name, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdin pipe: %w", err)
+	}
+
+	go func() {
+		defer stdin.Close()
+		for _, line := range p.Lines(ranked) {
+			fmt.Fprint(stdin, line, sep)
+		}
+	}()
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s exited with error or no selection: %w", p.name, err)
+	}
+
+	var selected []string
+	for _, line := range strings.Split(string(output), sep) {
+		if line == "" {
+			continue
+		}
+		path, err := p.Selection(line)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, path)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no selection")
+	}
+	return selected, nil
+}
+
+// builtinPicker is the terminal picker from the picker package.
+type builtinPicker struct{}
+
+// Pick runs the built-in terminal picker over candidates, most frecent first.
+func (builtinPicker) Pick(candidates []*db.Directory, opts PickOptions) ([]string, error) {
+	tty, err := picker.OpenTTY()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	popts := picker.Options{
+		Multi: opts.Multi,
+		Query: opts.Query,
+		Match: matchWithMarks(candidates),
+	}
+	if opts.Manage {
+		popts.Multi = true
+		for _, b := range manageBindings {
+			action := b.action
+			popts.Actions = append(popts.Actions, picker.Action{
+				Key:   b.key,
+				Label: b.label,
+				Run: func(paths []string) ([]picker.Item, error) {
+					if err := ApplyManageAction(opts.DataPath, action, paths); err != nil {
+						return nil, err
+					}
+					candidates, err := loadCandidates(opts.DataPath, opts.Filter)
+					if err != nil {
+						return nil, err
+					}
+					return BuiltinItems(candidates), nil
+				},
+			})
+		}
+	}
+	return picker.Run(tty, BuiltinItems(candidates), popts)
+}
+
+// matchWithMarks is MatchByKeywords, except that a single :name keyword matches
+// the candidates whose bookmark starts with name.
+func matchWithMarks(candidates []*db.Directory) func(string, []string) bool {
+	marks := map[string]string{}
+	for _, dir := range candidates {
+		if dir.Mark != "" {
+			marks[dir.Path] = dir.Mark
+		}
+	}
+	return func(path string, keywords []string) bool {
+		if name, ok := markQuery(keywords); ok {
+			mark, marked := marks[path]
+			return marked && strings.HasPrefix(mark, name)
+		}
+		return MatchByKeywords(path, keywords)
+	}
+}
+
+// RankByFrecency scores dirs and orders them by descending frecency,
+// breaking ties the same way as QueryTop.
+func RankByFrecency(dirs []*db.Directory) []ScoredMatch {
+	now := time.Now()
+	ranked := make([]ScoredMatch, len(dirs))
+	for i, dir := range dirs {
+		ranked[i] = ScoredMatch{Path: dir, Frecency: WeighFrecencyAt(dir, now)}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return Better(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+// ttyAvailable reports whether there is a terminal to show a picker on.
+func ttyAvailable() bool {
+	tty, err := picker.OpenTTY()
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// BuiltinItems converts dirs into picker items ordered by descending frecency,
+// labelled with the same score and last-visit columns as fzf.
+func BuiltinItems(dirs []*db.Directory) []picker.Item {
+	now := time.Now()
+	ranked := RankByFrecency(dirs)
+	items := make([]picker.Item, len(ranked))
+	for i, m := range ranked {
+		lastVisit := time.Unix(int64(m.Path.LastVisit), 0)
+		items[i] = picker.Item{
+			Value: m.Path.Path,
+			Label: fmt.Sprintf("%8.2f%s  %-9s  %s", m.Frecency, pinMark(m.Path), HumanizeSince(lastVisit, now), EscapePath(m.Path.Path)),
+		}
+		if m.Path.Mark != "" {
+			items[i].Label += "  " + bookmarkLabel(m.Path)
+		}
+	}
+	return items
+}
+
+// SplitArgs splits s into arguments the way a POSIX shell would for a simple
+// command line: on unquoted whitespace, honouring single quotes, double quotes
+// and backslash escapes. It is used for the GOZELLE_*_OPTS variables.
+func SplitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestBuiltinItemsOrderedByFrecency(t *testing.T) {
+	now := db.Age(time.Now().Unix())
+	dirs := []*db.Directory{
+		{Path: "/low", Score: 1, LastVisit: now},
+		{Path: "/high", Score: 10, LastVisit: now},
+		{Path: "/new\nline", Score: 5, LastVisit: now},
+	}
+
+	items := BuiltinItems(dirs)
+	if items[0].Value != "/high" || items[1].Value != "/new\nline" || items[2].Value != "/low" {
+		t.Fatalf("unexpected order: %v", items)
+	}
+	if !strings.HasSuffix(items[1].Label, `just now   /new\nline`) {
+		t.Fatalf("expected label with columns and an escaped path, got %q", items[1].Label)
+	}
+}
+
+func TestResolvePicker(t *testing.T) {
+	// PATH only holds the stub pickers installed below
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("GOZELLE_PICKER", "")
+	install := func(name string) {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if p, err := ResolvePicker(PickerBuiltin); err != nil || p != PickerBuiltin {
+		t.Fatalf("expected builtin picker, got %q (%v)", p, err)
+	}
+	if p, err := ResolvePicker(PickerAuto); err != nil || p != PickerBuiltin {
+		t.Fatalf("expected auto to fall back to builtin, got %q (%v)", p, err)
+	}
+	if _, err := ResolvePicker(PickerFzf); err == nil {
+		t.Fatal("expected error for a picker that is not installed")
+	}
+	if _, err := ResolvePicker("dmenu"); err == nil {
+		t.Fatal("expected error for unknown picker")
+	}
+
+	install(PickerPeco)
+	install(PickerSkim)
+	if p, err := ResolvePicker(PickerAuto); err != nil || p != PickerSkim {
+		t.Fatalf("expected auto to prefer sk over peco, got %q (%v)", p, err)
+	}
+	install(PickerFzf)
+	if p, err := ResolvePicker(""); err != nil || p != PickerFzf {
+		t.Fatalf("expected auto to prefer fzf, got %q (%v)", p, err)
+	}
+	if p, err := ResolvePicker(PickerPeco); err != nil || p != PickerPeco {
+		t.Fatalf("expected the installed peco, got %q (%v)", p, err)
+	}
+
+	t.Setenv("GOZELLE_PICKER", PickerBuiltin)
+	if p, err := ResolvePicker(""); err != nil || p != PickerBuiltin {
+		t.Fatalf("expected GOZELLE_PICKER to select builtin, got %q (%v)", p, err)
+	}
+}
+
+func TestExternalPickerArgs(t *testing.T) {
+	t.Setenv("GOZELLE_PICKER_OPTS", "--cycle")
+	t.Setenv("GOZELLE_FZF_OPTS", `--height 40% --prompt 'dir> '`)
+
+	args, err := externalPickers[PickerFzf].Args(PickOptions{Multi: true, Query: "api web"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(args[:5], []string{"--ansi", "--read0", "--print0", "--query", "api web"}) {
+		t.Fatalf("unexpected leading args %q", args)
+	}
+	want := []string{"--multi", "--cycle", "--height", "40%", "--prompt", "dir> "}
+	if !reflect.DeepEqual(args[len(args)-len(want):], want) {
+		t.Fatalf("expected args to end with %q, got %q", want, args)
+	}
+
+	args, _ = externalPickers[PickerFzy].Args(PickOptions{Query: "api"})
+	if !reflect.DeepEqual(args, []string{"--query", "api", "--cycle"}) {
+		t.Fatalf("unexpected fzy args %q", args)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	got, err := SplitArgs(`a "b c" 'd "e"' f\ g`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"a", "b c", `d "e"`, "f g"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	if got, _ := SplitArgs("   "); len(got) != 0 {
+		t.Fatalf("expected no args, got %q", got)
+	}
+	if _, err := SplitArgs(`"unterminated`); err == nil {
+		t.Fatal("expected error for unterminated quote")
+	}
+}
+
+func TestExternalPickerPick(t *testing.T) {
+	bin := t.TempDir()
+	// a stand-in for fzy that echoes its query and picks the second candidate
+	script := "#!/bin/sh\necho \"$2\" > \"$(dirname \"$0\")/query\"\nsed -n 2p\n"
+	if err := os.WriteFile(filepath.Join(bin, PickerFzy), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake picker: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	name, err := ResolvePicker(PickerFzy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, _ := NewPicker(name)
+	dirs := []*db.Directory{{Path: "/a"}, {Path: "/b\nc"}, {Path: "/d"}}
+	got, err := p.Pick(dirs, PickOptions{Query: "seed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the path containing a newline is not offered to newline-delimited pickers
+	if !reflect.DeepEqual(got, []string{"/d"}) {
+		t.Fatalf("expected [/d], got %q", got)
+	}
+	query, _ := os.ReadFile(filepath.Join(bin, "query"))
+	if string(query) != "seed\n" {
+		t.Fatalf("expected picker to receive the seed query, got %q", query)
+	}
+}
+
+func TestExternalPickerColumns(t *testing.T) {
+	now := db.Age(time.Now().Unix())
+	ranked := RankByFrecency([]*db.Directory{
+		{Path: "/low", Score: 1, LastVisit: now},
+		{Path: "/with\ttab", Score: 10, LastVisit: now},
+	})
+
+	fzf := externalPickers[PickerFzf]
+	lines := fzf.Lines(ranked)
+	if lines[0] != "0\t   10.00  \tjust now \t\t/with\ttab" {
+		t.Fatalf("unexpected line %q", lines[0])
+	}
+	for i, line := range lines {
+		path, err := fzf.Selection(line)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if path != ranked[i].Path.Path {
+			t.Fatalf("expected %q, got %q", ranked[i].Path.Path, path)
+		}
+	}
+	if _, err := fzf.Selection("7\tbogus"); err == nil {
+		t.Fatal("expected error for a malformed line")
+	}
+
+	if lines := externalPickers[PickerPeco].Lines(ranked); !reflect.DeepEqual(lines, []string{"/with\ttab", "/low"}) {
+		t.Fatalf("expected plain paths for peco, got %q", lines)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0o644)
+
+	var buf bytes.Buffer
+	if err := Preview(&buf, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := dir + "\nsub/\na.txt\n"; buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+
+	if err := Preview(&buf, filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected error for a missing directory")
+	}
+}
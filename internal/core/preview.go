@@ -0,0 +1,34 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Preview writes the contents of the directory at path to w, subdirectories
+// first and marked with a trailing slash. It backs the picker preview pane.
+func Preview(w io.Writer, path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("cannot preview %s: %w", EscapePath(path), err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].IsDir() && !entries[j].IsDir()
+	})
+
+	fmt.Fprintln(w, EscapePath(path))
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "(empty)")
+	}
+	for _, e := range entries {
+		name := EscapePath(e.Name())
+		if e.IsDir() {
+			name += "/"
+		}
+		fmt.Fprintln(w, name)
+	}
+	return nil
+}
@@ -3,10 +3,10 @@ package core
 import (
 	"fmt"
 	"log"
-	"os/exec"
 	"runtime"
-	"strings"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/atliod/gozelle/internal/db"
 )
@@ -16,11 +16,42 @@ type ScoredMatch struct {
 	Frecency float64
 }
 
+// QueryOptions configures QueryTopWithOptions.
+type QueryOptions struct {
+	Filter Filter // restricts the candidates
+	// AmbiguityRatio enables prompting when the top matches are close: if the
+	// runner-up's frecency is at least this fraction of the winner's, the matches
+	// within that ratio are offered in Picker instead of jumping. 0 disables it.
+	AmbiguityRatio float64
+	Picker         string // picker name as returned by ResolvePicker
+	Incognito      bool   // leave the chosen directory's score and last visit alone
+}
+
 // QueryTop searches for the best match in the directories based on keywords.
 func QueryTop(keywords []string, path string) ScoredMatch {
-	if len(keywords) == 0 {
-		fmt.Print("./")
-		return ScoredMatch{}
+	return QueryTopFiltered(keywords, path, Filter{})
+}
+
+// QueryTopFiltered is QueryTop restricted to the entries allowed by filter.
+func QueryTopFiltered(keywords []string, path string, filter Filter) ScoredMatch {
+	match, _ := QueryTopWithOptions(keywords, path, QueryOptions{Filter: filter})
+	return match
+}
+
+// QueryTopWithOptions is QueryTop configured by opts. Unless opts.Incognito is
+// set, the chosen directory is recorded as a jump, see Increment. An @tag among
+// keywords restricts the candidates to the entries carrying the tag, and on its
+// own jumps to the best of them. A single :name keyword resolves to the
+// bookmark of that name regardless of scores, or to no match. Pinned entries
+// win over any other match, see Better. An error is only returned for an
+// invalid @tag or when the user cancels the ambiguity prompt.
+func QueryTopWithOptions(keywords []string, path string, opts QueryOptions) (ScoredMatch, error) {
+	filter, keywords, err := ScopeToTag(opts.Filter, keywords)
+	if err != nil {
+		return ScoredMatch{}, err
+	}
+	if len(keywords) == 0 && filter.Tag == "" {
+		return ScoredMatch{}, nil
 	}
 
 	database, err := db.NewDirectoryManagerWithPath(path)
@@ -28,20 +59,67 @@ func QueryTop(keywords []string, path string) ScoredMatch {
 		panic(err)
 	}
 
+	if name, ok := markQuery(keywords); ok {
+		dir, err := database.Marked(name)
+		if err != nil {
+			return ScoredMatch{}, nil
+		}
+		return recordJump(database, ScoredMatch{Path: dir, Frecency: WeighFrecency(dir)}, opts)
+	}
+
+	var ranked []ScoredMatch
+	if len(keywords) == 0 {
+		// a lone @tag jumps to the best entry carrying it
+		ranked = RankByFrecency(filterEntries(database.Entries, filter))
+	} else {
+		ranked = rankMatches(database.Entries, keywords, filter)
+	}
+	if len(ranked) == 0 {
+		return ScoredMatch{}, nil
+	}
+	bestMatch := ranked[0]
+
+	if candidates := Ambiguous(ranked, opts.AmbiguityRatio); len(candidates) > 1 && ttyAvailable() {
+		bestMatch, err = pickAmbiguous(candidates, opts.Picker)
+		if err != nil {
+			return ScoredMatch{}, err
+		}
+	}
+
+	return recordJump(database, bestMatch, opts)
+}
+
+// recordJump records the jump to match unless opts.Incognito is set.
+func recordJump(database *db.DirectoryManager, match ScoredMatch, opts QueryOptions) (ScoredMatch, error) {
+	if opts.Incognito {
+		return match, nil
+	}
+	match.Path = database.Visit(match.Path.Path, db.VisitJump, Increment(db.VisitJump))
+	if err := database.Save(); err != nil {
+		log.Println("Error saving database:", err)
+		panic(err)
+	}
+	return match, nil
+}
+
+// rankMatches scores the entries matching keywords on a worker pool and
+// returns them best first. Ties are broken deterministically, see Better.
+func rankMatches(entries []*db.Directory, keywords []string, filter Filter) []ScoredMatch {
 	jobs := make(chan *db.Directory)
 	results := make(chan ScoredMatch)
 	var wg sync.WaitGroup
+	now := time.Now()
 
 	// start workers
 	numWorkers := runtime.NumCPU()
 	for range numWorkers {
 		wg.Add(1)
-		go worker(jobs, results, keywords, &wg)
+		go worker(jobs, results, keywords, filter, now, &wg)
 	}
 
 	// feed jobs
 	go func() {
-		for _, dir := range database.Entries {
+		for _, dir := range entries {
 			jobs <- dir
 		}
 		close(jobs)
@@ -53,83 +131,107 @@ func QueryTop(keywords []string, path string) ScoredMatch {
 		close(results)
 	}()
 
-	// find best match
-	var bestMatch ScoredMatch
+	// results arrive in no particular order, so sort them
+	var ranked []ScoredMatch
 	for match := range results {
-		if match.Frecency > bestMatch.Frecency {
-			bestMatch = match
-		}
-	}
-	if bestMatch.Path == nil {
-		fmt.Print("./")
-		return bestMatch
-	}
-	bestMatch.Path.UpdateLastVisit()
-	bestMatch.Path.UpdateScore()
-	database.Dirty = true
-	if err := database.Save(); err != nil {
-		log.Println("Error saving database:", err)
-		panic(err)
+		ranked = append(ranked, match)
 	}
-	fmt.Print(bestMatch.Path.Path)
-	return bestMatch
+	sort.Slice(ranked, func(i, j int) bool {
+		return Better(ranked[i], ranked[j])
+	})
+	return ranked
 }
 
-func worker(jobs <-chan *db.Directory, results chan<- ScoredMatch, keywords []string, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for dir := range jobs {
-		if MatchByKeywords(dir.Path, keywords) {
-			score := WeighFrecency(dir)
-			results <- ScoredMatch{Path: dir, Frecency: score}
-		}
+// Ambiguous returns the leading matches of ranked whose frecency is at least
+// ratio times the winner's, or nil if the runner-up is not that close or ratio is 0.
+// A pinned winner is only challenged by other pinned matches.
+func Ambiguous(ranked []ScoredMatch, ratio float64) []ScoredMatch {
+	if ratio <= 0 || len(ranked) < 2 {
+		return nil
+	}
+	threshold := ranked[0].Frecency * ratio
+	n := 1
+	for n < len(ranked) && ranked[n].Path.Pinned == ranked[0].Path.Pinned && ranked[n].Frecency >= threshold {
+		n++
 	}
+	if n < 2 {
+		return nil
+	}
+	return ranked[:n]
 }
 
-func QueryInteractive(path string, multi bool) (string, error) {
-	dm, err := db.NewDirectoryManagerWithPath(path)
+// pickAmbiguous lets the user choose among close matches with the given picker.
+func pickAmbiguous(candidates []ScoredMatch, pickerName string) (ScoredMatch, error) {
+	name, err := ResolvePicker(pickerName)
 	if err != nil {
-		return "", fmt.Errorf("failed to load directory manager: %w", err)
-	}
-
-	if len(dm.Entries) == 0 {
-		return "", fmt.Errorf("no directories found in datastore")
+		return ScoredMatch{}, err
 	}
-
-	lines := make([]string, len(dm.Entries))
-	for i, dir := range dm.Entries {
-		lines[i] = dir.Path
+	p, err := NewPicker(name)
+	if err != nil {
+		return ScoredMatch{}, err
 	}
 
-	args := []string{"--ansi"}
-	if multi {
-		args = append(args, "--multi")
+	dirs := make([]*db.Directory, len(candidates))
+	for i, m := range candidates {
+		dirs[i] = m.Path
 	}
-
-	cmd := exec.Command("fzf", args...)
-	stdin, err := cmd.StdinPipe()
+	selected, err := p.Pick(dirs, PickOptions{})
 	if err != nil {
-		return "", fmt.Errorf("failed to get stdin pipe: %w", err)
+		return ScoredMatch{}, err
+	}
+	for _, m := range candidates {
+		if m.Path.Path == selected[0] {
+			return m, nil
+		}
 	}
+	return ScoredMatch{}, fmt.Errorf("unexpected selection %q", selected[0])
+}
 
-	go func() {
-		defer stdin.Close()
-		for _, line := range lines {
-			fmt.Fprintln(stdin, line)
+func worker(jobs <-chan *db.Directory, results chan<- ScoredMatch, keywords []string, filter Filter, now time.Time, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for dir := range jobs {
+		if filter.Allows(dir) && MatchByKeywords(dir.Path, keywords) {
+			score := WeighFrecencyAt(dir, now)
+			results <- ScoredMatch{Path: dir, Frecency: score}
 		}
-	}()
+	}
+}
 
-	output, err := cmd.Output()
+// InteractiveOptions configures QueryInteractive.
+type InteractiveOptions struct {
+	Multi  bool   // allow selecting several directories
+	Filter Filter // restricts the candidates
+	Picker string // picker name as returned by ResolvePicker
+	Query  string // initial query typed into the picker
+	Manage bool   // enable the delete, pin and rescore bindings
+}
+
+// QueryInteractive lets the user pick directories and returns the selected paths.
+func QueryInteractive(path string, opts InteractiveOptions) ([]string, error) {
+	p, err := NewPicker(opts.Picker)
 	if err != nil {
-		return "", fmt.Errorf("fzf exited with error or no selection: %w", err)
+		return nil, err
+	}
+	if ext, ok := p.(externalPicker); ok && opts.Manage && !ext.columns {
+		return nil, fmt.Errorf("--manage is not supported by %s, use fzf, sk or the builtin picker", ext.name)
 	}
 
-	selectedLines := strings.Split(strings.TrimSpace(string(output)), "\n")
-
-	var sel string
-	// Print selected directories to stdout
-	for _, sel = range selectedLines {
-		fmt.Print(sel, "\n")
+	candidates, err := loadCandidates(path, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		if opts.Filter != (Filter{}) {
+			return nil, fmt.Errorf("no directories match the given filters")
+		}
+		return nil, fmt.Errorf("no directories found in datastore")
 	}
 
-	return sel, nil
+	return p.Pick(candidates, PickOptions{
+		Multi:    opts.Multi,
+		Query:    opts.Query,
+		Manage:   opts.Manage,
+		DataPath: path,
+		Filter:   opts.Filter,
+	})
 }
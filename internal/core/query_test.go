@@ -3,6 +3,7 @@ package core
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/atliod/gozelle/internal/db"
 )
@@ -30,8 +31,8 @@ func TestQueryTop(t *testing.T) {
 	if bestMatch.Frecency <= 0 {
 		t.Fatalf("expected positive frecency, got %f", bestMatch.Frecency)
 	}
-	if bestMatch.Path.Score != 4*1.05 {
-		t.Fatalf("expected score 4.2, got %f", bestMatch.Path.Score)
+	if bestMatch.Path.Score != 4+DefaultIncrements[db.VisitJump] {
+		t.Fatalf("expected score 6, got %f", bestMatch.Path.Score)
 	}
 	if bestMatch.Path.LastVisit == 0 {
 		t.Fatalf("expected non-zero last visit, got %d", bestMatch.Path.LastVisit)
@@ -52,8 +53,8 @@ func TestQueryTop(t *testing.T) {
 	if bestMatchPath2.Frecency <= 0 {
 		t.Fatalf("expected positive frecency, got %f", bestMatch.Frecency)
 	}
-	if bestMatchPath2.Path.Score != 5*1.05 {
-		t.Fatalf("expected score 5.25, got %f", bestMatch.Path.Score)
+	if bestMatchPath2.Path.Score != 5+DefaultIncrements[db.VisitJump] {
+		t.Fatalf("expected score 7, got %f", bestMatch.Path.Score)
 	}
 	if bestMatchPath2.Path.LastVisit == 0 {
 		t.Fatalf("expected non-zero last visit, got %d", bestMatch.Path.LastVisit)
@@ -70,8 +71,8 @@ func TestQueryTop(t *testing.T) {
 	if bestMatchDifferent.Frecency <= 0 {
 		t.Fatalf("expected positive frecency, got %f", bestMatch.Frecency)
 	}
-	if bestMatchDifferent.Path.Score != 1*1.05 {
-		t.Fatalf("expected score 1.05, got %f", bestMatch.Path.Score)
+	if bestMatchDifferent.Path.Score != 1+DefaultIncrements[db.VisitJump] {
+		t.Fatalf("expected score 3, got %f", bestMatchDifferent.Path.Score)
 	}
 	if bestMatchDifferent.Path.LastVisit == 0 {
 		t.Fatalf("expected non-zero last visit, got %d", bestMatch.Path.LastVisit)
@@ -95,3 +96,115 @@ func TestQueryTop(t *testing.T) {
 		t.Fatalf("expected frecency 0, got %f", bestMatch.Frecency)
 	}
 }
+
+func TestQueryTopTieBreak(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	now := db.Age(time.Now().Unix())
+	dm.Add("/long/path/api")
+	dm.Add("/c/api")
+	dm.Add("/b/api")
+	dm.Add("/a/api")
+	for _, dir := range dm.Entries {
+		dir.Score = 2
+		dir.LastVisit = now
+	}
+	dm.Dirty = true
+	dm.Save()
+
+	// equal frecency: shorter paths first, then lexical order
+	ranked := rankMatches(dm.Entries, []string{"api"}, Filter{})
+	want := []string{"/a/api", "/b/api", "/c/api", "/long/path/api"}
+	for i, m := range ranked {
+		if m.Path.Path != want[i] {
+			t.Fatalf("position %d: expected %s, got %s", i, want[i], m.Path.Path)
+		}
+	}
+
+	// repeated queries must agree even though results arrive from several goroutines
+	for range 20 {
+		if got := rankMatches(dm.Entries, []string{"api"}, Filter{})[1].Path.Path; got != "/b/api" {
+			t.Fatalf("expected /b/api to rank second, got %s", got)
+		}
+	}
+}
+
+func TestBetterTieBreak(t *testing.T) {
+	match := func(path string, lastVisit db.Age) ScoredMatch {
+		return ScoredMatch{Path: &db.Directory{Path: path, LastVisit: lastVisit}, Frecency: 2}
+	}
+	cases := []struct {
+		name          string
+		better, worse ScoredMatch
+	}{
+		{"shorter path", match("/a/api", 100), match("/long/path/api", 200)},
+		{"more recent visit", match("/c/api", 200), match("/a/api", 100)},
+		{"lexically smaller path", match("/a/api", 100), match("/b/api", 100)},
+	}
+	for _, c := range cases {
+		if !Better(c.better, c.worse) || Better(c.worse, c.better) {
+			t.Fatalf("%s: expected %s to rank before %s", c.name, c.better.Path.Path, c.worse.Path.Path)
+		}
+	}
+}
+
+func TestAmbiguous(t *testing.T) {
+	ranked := []ScoredMatch{
+		{Path: &db.Directory{Path: "/a"}, Frecency: 10},
+		{Path: &db.Directory{Path: "/b"}, Frecency: 9.5},
+		{Path: &db.Directory{Path: "/c"}, Frecency: 9},
+		{Path: &db.Directory{Path: "/d"}, Frecency: 2},
+	}
+
+	if got := Ambiguous(ranked, 0); got != nil {
+		t.Fatalf("expected no prompt when disabled, got %v", got)
+	}
+	if got := Ambiguous(ranked, 0.9); len(got) != 3 {
+		t.Fatalf("expected 3 close matches, got %d", len(got))
+	}
+	if got := Ambiguous(ranked, 0.99); got != nil {
+		t.Fatalf("expected a clear winner, got %v", got)
+	}
+	if got := Ambiguous(ranked[:1], 0.5); got != nil {
+		t.Fatalf("expected no prompt for a single match, got %v", got)
+	}
+}
+
+func TestQueryTopIncognito(t *testing.T) {
+	dm, err := db.CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+	dm.QueryDummyData()
+
+	best, err := QueryTopWithOptions([]string{"test"}, dm.FilePath, QueryOptions{Incognito: true})
+	if err != nil || best.Path == nil {
+		t.Fatalf("expected a match, got %v, %v", best.Path, err)
+	}
+
+	reloaded, err := db.NewDirectoryManagerWithPath(dm.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := reloaded.Get(best.Path.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Score != 4 || stored.LastVisit != best.Path.LastVisit {
+		t.Fatalf("expected an incognito jump to leave the entry alone, got score %v", stored.Score)
+	}
+}
+
+func TestIncognito(t *testing.T) {
+	for val, want := range map[string]bool{"": false, "1": true, "0": true, "false": true} {
+		t.Setenv(IncognitoEnv, val)
+		if got := Incognito(); got != want {
+			t.Errorf("%s=%q: expected %v, got %v", IncognitoEnv, val, want, got)
+		}
+	}
+}
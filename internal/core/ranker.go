@@ -11,16 +11,41 @@ import (
 
 // WeighFrecency calculates the frecency score of a Directory instance based on its LastVisit time and Score.
 func WeighFrecency(dir *db.Directory) float64 {
+	return WeighFrecencyAt(dir, time.Now())
+}
+
+// WeighFrecencyAt is WeighFrecency as of now. Scoring a whole set of entries
+// against the same instant keeps equal entries equal.
+func WeighFrecencyAt(dir *db.Directory, now time.Time) float64 {
 	const halfLifeDecay float64 = 0.693
 	// NOTE: minimumWeight is a base score to ensure that even old entries have some weight
 	minimumWeight, _ := strconv.ParseFloat(os.Getenv("GOZELLE_MINIMUM_WEIGHT"), 64)
 
 	lastVisitTime := time.Unix(int64(dir.LastVisit), 0) // Convert age to int64 then to time.Time
 
-	elapsedTime := time.Since(lastVisitTime)
+	elapsedTime := now.Sub(lastVisitTime)
 
 	decayFactor := math.Exp(-halfLifeDecay * elapsedTime.Hours())
 
 	// Return the frecency score
 	return minimumWeight + float64(dir.Score)*decayFactor
 }
+
+// Better reports whether a ranks before b: a pinned entry always wins over an
+// unpinned one, then higher frecency wins, ties go to the shorter path, then the
+// more recent visit, then the lexically smaller path.
+func Better(a, b ScoredMatch) bool {
+	if a.Path.Pinned != b.Path.Pinned {
+		return a.Path.Pinned
+	}
+	if a.Frecency != b.Frecency {
+		return a.Frecency > b.Frecency
+	}
+	if len(a.Path.Path) != len(b.Path.Path) {
+		return len(a.Path.Path) < len(b.Path.Path)
+	}
+	if a.Path.LastVisit != b.Path.LastVisit {
+		return a.Path.LastVisit > b.Path.LastVisit
+	}
+	return a.Path.Path < b.Path.Path
+}
@@ -2,11 +2,14 @@ package core
 
 import "github.com/atliod/gozelle/internal/db"
 
+// Remove deletes the entry of path, given in any form CanonicalPath accepts or
+// exactly as stored.
 func Remove(path string) error {
 	database, err := db.NewDirectoryManager()
 	if err != nil {
 		panic(err)
 	}
+	path = storedPath(database, path)
 
 	// find the path in the database
 	for i, dir := range database.Entries {
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// DefaultBoostFactor is what gozelle boost and demote multiply or divide a
+// score by when no factor is given.
+const DefaultBoostFactor = 2.0
+
+// ScaleScore multiplies the score of path in the store at dataPath by factor,
+// which must be positive, and returns the new score. The path is added if it is
+// not stored yet, see storedOrAdded.
+func ScaleScore(dataPath, path string, factor float64) (db.Score, error) {
+	if factor <= 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return 0, fmt.Errorf("invalid factor %v: must be a positive number", factor)
+	}
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	dir, err := storedOrAdded(database, path)
+	if err != nil {
+		return 0, err
+	}
+	score, err := database.ScaleScore(dir.Path, db.Score(factor))
+	if err != nil {
+		return 0, err
+	}
+	return score, database.Save()
+}
+
+// SetScore sets the score of path in the store at dataPath, adding it if it is
+// not stored yet.
+func SetScore(dataPath, path string, score float64) error {
+	if score < 0 || math.IsInf(score, 0) || math.IsNaN(score) {
+		return fmt.Errorf("invalid score %v: must be a number of at least 0", score)
+	}
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	dir, err := storedOrAdded(database, path)
+	if err != nil {
+		return err
+	}
+	if err := database.SetScore(dir.Path, db.Score(score)); err != nil {
+		return err
+	}
+	return database.Save()
+}
+
+// SetPinned pins or unpins path in the store at dataPath. Pinning adds the path
+// if it is not stored yet. A pinned entry ranks before any unpinned match.
+func SetPinned(dataPath, path string, pinned bool) error {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	if pinned {
+		dir, err := storedOrAdded(database, path)
+		if err != nil {
+			return err
+		}
+		path = dir.Path
+	} else {
+		path = storedPath(database, path)
+	}
+	if err := database.SetPinned(path, pinned); err != nil {
+		return err
+	}
+	return database.Save()
+}
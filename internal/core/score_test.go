@@ -0,0 +1,63 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestManualScores(t *testing.T) {
+	dataPath, dirs := newTestTree(t, "quiet/api", "busy/api")
+	quiet, busy := dirs[0], dirs[1]
+	if err := RecordVisit(dataPath, db.VisitCd, quiet, busy, busy, busy); err != nil {
+		t.Fatal(err)
+	}
+
+	if score, err := ScaleScore(dataPath, quiet, 8); err != nil || score != 8 {
+		t.Fatalf("expected boosted score 8, got %v, %v", score, err)
+	}
+	if score, err := ScaleScore(dataPath, quiet, 1/DefaultBoostFactor); err != nil || score != 4 {
+		t.Fatalf("expected demoted score 4, got %v, %v", score, err)
+	}
+	if _, err := ScaleScore(dataPath, quiet, 0); err == nil {
+		t.Fatal("expected a zero factor to be refused")
+	}
+	if err := SetScore(dataPath, quiet, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetScore(dataPath, quiet, -1); err == nil {
+		t.Fatal("expected a negative score to be refused")
+	}
+	if best := QueryTop([]string{"api"}, dataPath); best.Path == nil || best.Path.Path != busy {
+		t.Fatalf("expected the busier directory to win before pinning, got %v", best.Path)
+	}
+
+	if err := SetPinned(dataPath, quiet, true); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if best := QueryTop([]string{"api"}, dataPath); best.Path == nil || best.Path.Path != quiet {
+			t.Fatalf("expected the pinned directory to win, got %v", best.Path)
+		}
+	}
+	if err := SetPinned(dataPath, quiet, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPinned(dataPath, filepath.Join(filepath.Dir(dataPath), "missing"), false); err == nil {
+		t.Fatal("expected unpinning a missing directory to fail")
+	}
+}
+
+func TestAmbiguousPinned(t *testing.T) {
+	ranked := []ScoredMatch{
+		{Path: &db.Directory{Path: "/a", Pinned: true}, Frecency: 1},
+		{Path: &db.Directory{Path: "/b"}, Frecency: 10},
+	}
+	if got := Ambiguous(ranked, 0.1); got != nil {
+		t.Fatalf("expected a pinned winner not to be challenged, got %v", got)
+	}
+	if !Better(ranked[0], ranked[1]) {
+		t.Fatal("expected a pinned entry to rank first")
+	}
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// TagPrefix marks a keyword naming a tag, as in gz @work api.
+const TagPrefix = "@"
+
+// validName matches the tag and bookmark names that can be typed after their
+// prefix without quoting.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateTag rejects tag names that could not be typed as @tag keywords.
+func ValidateTag(tag string) error {
+	if !validName.MatchString(tag) {
+		return fmt.Errorf("invalid tag %q: use letters, digits, _, . and -", tag)
+	}
+	return nil
+}
+
+// SplitTag separates an @tag keyword from keywords and returns the tag name,
+// or "" if there is none, and the other keywords.
+func SplitTag(keywords []string) (string, []string, error) {
+	tag := ""
+	rest := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		name, ok := strings.CutPrefix(k, TagPrefix)
+		if !ok {
+			rest = append(rest, k)
+			continue
+		}
+		if tag != "" && name != tag {
+			return "", nil, fmt.Errorf("only one @tag can be given, got @%s and %s", tag, k)
+		}
+		if err := ValidateTag(name); err != nil {
+			return "", nil, err
+		}
+		tag = name
+	}
+	return tag, rest, nil
+}
+
+// ScopeToTag moves an @tag keyword out of keywords into the filter. It fails
+// if the filter already names a different tag.
+func ScopeToTag(filter Filter, keywords []string) (Filter, []string, error) {
+	tag, rest, err := SplitTag(keywords)
+	if err != nil || tag == "" {
+		return filter, rest, err
+	}
+	if filter.Tag != "" && filter.Tag != tag {
+		return filter, nil, fmt.Errorf("--tag %s conflicts with @%s", filter.Tag, tag)
+	}
+	filter.Tag = tag
+	return filter, rest, nil
+}
+
+// TagCount is a tag and the number of entries carrying it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// Tags returns the tags in the store at dataPath, by name.
+func Tags(dataPath string) ([]TagCount, error) {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	counts := map[string]int{}
+	for _, dir := range database.Entries {
+		for _, tag := range dir.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]TagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, TagCount{name, count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// CompleteTags returns the tags in the store at dataPath starting with prefix.
+func CompleteTags(dataPath, prefix string) []string {
+	tags, err := Tags(dataPath)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag.Name, prefix) {
+			names = append(names, tag.Name)
+		}
+	}
+	return names
+}
+
+// TagPaths adds tag to the entries of paths in the store at dataPath, adding
+// the paths that are not stored yet, see storedOrAdded.
+func TagPaths(dataPath, tag string, paths []string) error {
+	if err := ValidateTag(tag); err != nil {
+		return err
+	}
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+
+	for _, path := range paths {
+		dir, err := storedOrAdded(database, path)
+		if err != nil {
+			return err
+		}
+		if dir.AddTag(tag) {
+			database.Dirty = true
+		}
+	}
+	return database.Save()
+}
+
+// UntagPaths removes tag from the entries of paths in the store at dataPath,
+// or from every entry when no paths are given, and returns how many had it.
+func UntagPaths(dataPath, tag string, paths []string) (int, error) {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+
+	var dirs []*db.Directory
+	if len(paths) == 0 {
+		dirs = database.Entries
+	}
+	for _, path := range paths {
+		dir, err := database.Get(storedPath(database, path))
+		if err != nil {
+			return 0, err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	removed := 0
+	for _, dir := range dirs {
+		if dir.RemoveTag(tag) {
+			removed++
+		}
+	}
+	if removed > 0 {
+		database.Dirty = true
+	}
+	return removed, database.Save()
+}
+
+// PathTags returns the tags of the entry of path in the store at dataPath.
+func PathTags(dataPath, path string) ([]string, error) {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	dir, err := database.Get(storedPath(database, path))
+	if err != nil {
+		return nil, err
+	}
+	return dir.Tags, nil
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+func TestSplitTag(t *testing.T) {
+	tag, rest, err := SplitTag([]string{"@clientA", "api"})
+	if err != nil || tag != "clientA" || !slices.Equal(rest, []string{"api"}) {
+		t.Fatalf("unexpected split: %q, %v, %v", tag, rest, err)
+	}
+	if tag, rest, _ := SplitTag([]string{"api"}); tag != "" || len(rest) != 1 {
+		t.Fatalf("expected no tag, got %q, %v", tag, rest)
+	}
+	if _, _, err := SplitTag([]string{"@a", "@b"}); err == nil {
+		t.Fatal("expected two tags to be refused")
+	}
+	if _, _, err := SplitTag([]string{"@"}); err == nil {
+		t.Fatal("expected an empty tag to be refused")
+	}
+	if _, _, err := ScopeToTag(Filter{Tag: "a"}, []string{"@b"}); err == nil {
+		t.Fatal("expected --tag and a different @tag to conflict")
+	}
+}
+
+func TestTagPaths(t *testing.T) {
+	dataPath, dirs := newTestTree(t, "clientA/api", "clientA/web", "clientB/api")
+	api, web, other := dirs[0], dirs[1], dirs[2]
+	if err := RecordVisit(dataPath, db.VisitCd, other, other, other, api); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TagPaths(dataPath, "clientA", []string{api, web}); err != nil {
+		t.Fatal(err)
+	}
+	if err := TagPaths(dataPath, "clientA", []string{filepath.Join(filepath.Dir(dataPath), "missing")}); err == nil {
+		t.Fatal("expected tagging a missing directory to fail")
+	}
+	if err := TagPaths(dataPath, "bad tag", []string{api}); err == nil {
+		t.Fatal("expected an invalid tag to be refused")
+	}
+
+	tags, err := Tags(dataPath)
+	if err != nil || !slices.Equal(tags, []TagCount{{"clientA", 2}}) {
+		t.Fatalf("unexpected tags %v, %v", tags, err)
+	}
+	if got := CompleteTags(dataPath, "cl"); !slices.Equal(got, []string{"clientA"}) {
+		t.Fatalf("unexpected tag completions %v", got)
+	}
+	if got := Complete(dataPath, nil, "@c", CompletionLimit); !slices.Equal(got, []string{"@clientA"}) {
+		t.Fatalf("unexpected @ completions %v", got)
+	}
+
+	// the untagged entry has the best score but is out of scope
+	best, err := QueryTopWithOptions([]string{"@clientA", "api"}, dataPath, QueryOptions{Incognito: true})
+	if err != nil || best.Path == nil || best.Path.Path != api {
+		t.Fatalf("expected @clientA api to find %s, got %v, %v", api, best.Path, err)
+	}
+	best, err = QueryTopWithOptions([]string{"@clientA"}, dataPath, QueryOptions{Incognito: true})
+	if err != nil || best.Path == nil || !best.Path.HasTag("clientA") {
+		t.Fatalf("expected a lone @clientA to find a tagged entry, got %v, %v", best.Path, err)
+	}
+	if got := Complete(dataPath, []string{"@clientA"}, "api", CompletionLimit); !slices.Equal(got, []string{api}) {
+		t.Fatalf("expected completion scoped to the tag, got %v", got)
+	}
+
+	removed, err := UntagPaths(dataPath, "clientA", []string{web})
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one entry untagged, got %d, %v", removed, err)
+	}
+	if removed, _ := UntagPaths(dataPath, "clientA", nil); removed != 1 {
+		t.Fatalf("expected the remaining entry untagged, got %d", removed)
+	}
+	if tags, _ := Tags(dataPath); len(tags) != 0 {
+		t.Fatalf("expected no tags left, got %v", tags)
+	}
+}
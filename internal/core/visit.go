@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/atliod/gozelle/internal/db"
+)
+
+// DefaultIncrements are what a visit of each kind adds to a directory's score.
+// Choosing a directory with gz or gi says more than passing through it with cd;
+// the cd the jump causes is recorded by the hook on top.
+var DefaultIncrements = map[db.VisitKind]db.Score{
+	db.VisitCd:          1,
+	db.VisitJump:        2,
+	db.VisitInteractive: 2,
+	db.VisitManual:      1,
+}
+
+// IncrementEnv returns the environment variable overriding the increment of
+// kind, e.g. GOZELLE_INCREMENT_JUMP.
+func IncrementEnv(kind db.VisitKind) string {
+	return "GOZELLE_INCREMENT_" + strings.ToUpper(kind.String())
+}
+
+// Increment returns the score increment of a visit of kind.
+func Increment(kind db.VisitKind) db.Score {
+	if val, err := strconv.ParseFloat(os.Getenv(IncrementEnv(kind)), 64); err == nil && val >= 0 {
+		return db.Score(val)
+	}
+	return DefaultIncrements[kind]
+}
+
+// setIncrementConfig checks the increment variables, resetting invalid ones.
+func setIncrementConfig() {
+	for kind, increment := range DefaultIncrements {
+		env := IncrementEnv(kind)
+		val := os.Getenv(env)
+		if val == "" {
+			os.Setenv(env, strconv.FormatFloat(float64(increment), 'g', -1, 64))
+		} else if f, err := strconv.ParseFloat(val, 64); err != nil || f < 0 {
+			fmt.Println(env, "must be a non-negative number")
+			os.Setenv(env, strconv.FormatFloat(float64(increment), 'g', -1, 64))
+		}
+	}
+}
+
+// RecordVisit records a visit of kind to each of paths in the store at
+// dataPath, adding the ones it does not have.
+func RecordVisit(dataPath string, kind db.VisitKind, paths ...string) error {
+	database, err := db.NewDirectoryManagerWithPath(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to load directory manager: %w", err)
+	}
+	for _, path := range paths {
+		database.Visit(path, kind, Increment(kind))
+	}
+	return database.Save()
+}
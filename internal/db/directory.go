@@ -1,6 +1,9 @@
 package db
 
 import (
+	"fmt"
+	"math"
+	"slices"
 	"time"
 )
 
@@ -9,18 +12,132 @@ type (
 	Age   int64
 )
 
+// DefaultScore is the score of a newly added directory.
+const DefaultScore Score = 1 // NOTE: evaluate optimal default weight
+
 type Directory struct {
 	Path      string
 	LastVisit Age
 	Score     Score
+	Pinned    bool
+	Visits    Visits
+	FirstSeen Age      // when the entry was created
+	Recent    []Age    // the last RecentVisits visits, oldest first
+	LastJump  Age      // when gz or gi last jumped to it, 0 if never
+	Source    Source   // how the entry was created
+	Tags      []string // sorted
+	Mark      string   // the bookmark name resolving to it, "" if none
+}
+
+// RecentVisits is how many visit times an entry keeps.
+const RecentVisits = 10
+
+// Source is how an entry came into the store.
+type Source int
+
+const (
+	SourceUnknown Source = iota // created before sources were recorded
+	SourceHook                  // a visit recorded by the shell hook or a jump
+	SourceImport                // imported from another tool
+	SourceManual                // gozelle add run by hand
+)
+
+// Sources names the sources, indexed by Source.
+var Sources = []string{"unknown", "hook", "import", "manual"}
+
+func (s Source) String() string {
+	if s < 0 || int(s) >= len(Sources) {
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+	return Sources[s]
+}
+
+// SourceOf returns the source of an entry created by a visit of kind.
+func SourceOf(kind VisitKind) Source {
+	if kind == VisitManual {
+		return SourceManual
+	}
+	return SourceHook
+}
+
+// VisitKind is how a directory was visited.
+type VisitKind int
+
+const (
+	VisitCd          VisitKind = iota // the shell hook saw the working directory change
+	VisitJump                         // gz chose it
+	VisitInteractive                  // it was picked in interactive mode
+	VisitManual                       // gozelle add was run by hand
+)
+
+// VisitKinds names the visit kinds, indexed by VisitKind.
+var VisitKinds = []string{"cd", "jump", "interactive", "manual"}
+
+func (k VisitKind) String() string {
+	if k < 0 || int(k) >= len(VisitKinds) {
+		return fmt.Sprintf("VisitKind(%d)", int(k))
+	}
+	return VisitKinds[k]
+}
+
+// ParseVisitKind returns the visit kind named name.
+func ParseVisitKind(name string) (VisitKind, error) {
+	for i, kind := range VisitKinds {
+		if kind == name {
+			return VisitKind(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown visit kind %q (expected one of %v)", name, VisitKinds)
+}
+
+// Visits counts the visits to a directory by kind. Entries stored before the
+// counts were kept start at zero.
+type Visits struct {
+	Cd          int
+	Jump        int
+	Interactive int
+	Manual      int
+}
+
+// counter returns the count of kind.
+func (v *Visits) counter(kind VisitKind) *int {
+	switch kind {
+	case VisitJump:
+		return &v.Jump
+	case VisitInteractive:
+		return &v.Interactive
+	case VisitManual:
+		return &v.Manual
+	}
+	return &v.Cd
+}
+
+// Of returns the number of visits of kind.
+func (v Visits) Of(kind VisitKind) int {
+	return *v.counter(kind)
+}
+
+// Total returns the number of visits of all kinds.
+func (v Visits) Total() int {
+	return v.Cd + v.Jump + v.Interactive + v.Manual
+}
+
+// Merge adds the counts of other, e.g. of a duplicate entry.
+func (v *Visits) Merge(other Visits) {
+	v.Cd += other.Cd
+	v.Jump += other.Jump
+	v.Interactive += other.Interactive
+	v.Manual += other.Manual
 }
 
 // NewDirectory creates a new Directory instance with the given path, current time as LastVisit, and a default frecency score.
 func NewDirectory(path string) *Directory {
+	now := Age(time.Now().Unix())
 	return &Directory{
-		path,
-		Age(time.Now().Unix()),
-		Score(1), // NOTE: evaluate optimal default weight
+		Path:      path,
+		LastVisit: now,
+		Score:     DefaultScore,
+		FirstSeen: now,
 	}
 }
 
@@ -29,9 +146,96 @@ func (d *Directory) UpdateLastVisit() {
 	d.LastVisit = Age(time.Now().Unix())
 }
 
-// UpdateScore updates the Score field of the Directory instance by multiplying it by 2.
-func (d *Directory) UpdateScore() {
-	d.Score *= 1.05
+// Visit records a visit of kind: the score grows by increment, the kind's
+// counter by one and the visit time is remembered.
+func (d *Directory) Visit(kind VisitKind, increment Score) {
+	d.Score += increment
+	*d.Visits.counter(kind)++
+	d.UpdateLastVisit()
+	d.Recent = appendRecent(d.Recent, d.LastVisit)
+	if kind == VisitJump || kind == VisitInteractive {
+		d.LastJump = d.LastVisit
+	}
+}
+
+// HasTag reports whether d carries tag.
+func (d *Directory) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(d.Tags, tag)
+	return found
+}
+
+// AddTag adds tag to d and reports whether it was missing.
+func (d *Directory) AddTag(tag string) bool {
+	i, found := slices.BinarySearch(d.Tags, tag)
+	if found {
+		return false
+	}
+	d.Tags = slices.Insert(d.Tags, i, tag)
+	return true
+}
+
+// RemoveTag removes tag from d and reports whether it was there.
+func (d *Directory) RemoveTag(tag string) bool {
+	i, found := slices.BinarySearch(d.Tags, tag)
+	if !found {
+		return false
+	}
+	d.Tags = slices.Delete(d.Tags, i, i+1)
+	return true
 }
 
-// TODO: add a separate method to update on a gozelle visit, I want the gozelle visit to be weigthed differently than a cd visit
+// appendRecent appends visits to recent, keeping the latest RecentVisits in order.
+func appendRecent(recent []Age, visits ...Age) []Age {
+	recent = append(recent, visits...)
+	slices.Sort(recent)
+	if len(recent) > RecentVisits {
+		recent = slices.Clone(recent[len(recent)-RecentVisits:])
+	}
+	return recent
+}
+
+// Merge folds other, a duplicate entry for the same path, into d. As an entry
+// has at most one bookmark, other's is dropped when both have a different one;
+// its name is returned then, "" otherwise.
+func (d *Directory) Merge(other *Directory) (droppedMark string) {
+	d.Score += other.Score
+	d.LastVisit = max(d.LastVisit, other.LastVisit)
+	d.Pinned = d.Pinned || other.Pinned
+	d.Visits.Merge(other.Visits)
+	if d.FirstSeen == 0 || other.FirstSeen != 0 && other.FirstSeen < d.FirstSeen {
+		d.FirstSeen = other.FirstSeen
+	}
+	d.Recent = appendRecent(d.Recent, other.Recent...)
+	d.LastJump = max(d.LastJump, other.LastJump)
+	if d.Source == SourceUnknown {
+		d.Source = other.Source
+	}
+	for _, tag := range other.Tags {
+		d.AddTag(tag)
+	}
+	if d.Mark == "" {
+		d.Mark = other.Mark
+	} else if other.Mark != d.Mark {
+		droppedMark = other.Mark
+	}
+	return droppedMark
+}
+
+// addMetadata fills in what can be told about entries saved before they had
+// metadata: they were first seen no later than their last visit, which is the
+// one recent visit known, and as every visit used to add 1 to the score, the
+// score rounds to the number of cd visits. It upgrades stores from version 1.
+func addMetadata(dm *DirectoryManager) error {
+	for _, dir := range dm.Entries {
+		if dir.FirstSeen == 0 {
+			dir.FirstSeen = dir.LastVisit
+		}
+		if len(dir.Recent) == 0 && dir.LastVisit != 0 {
+			dir.Recent = []Age{dir.LastVisit}
+		}
+		if dir.Visits.Total() == 0 {
+			dir.Visits.Cd = max(1, int(math.Round(float64(dir.Score))))
+		}
+	}
+	return nil
+}
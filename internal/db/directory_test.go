@@ -1,6 +1,7 @@
 package db
 
 import (
+	"slices"
 	"testing"
 	"time"
 )
@@ -36,14 +37,105 @@ func TestUpdateLastVisit(t *testing.T) {
 	}
 }
 
-func TestUpdateScore(t *testing.T) {
-	path := "/path/to/directory"
-	dir := NewDirectory(path)
+func TestVisit(t *testing.T) {
+	dir := NewDirectory("/path/to/directory")
+	dir.LastVisit = 0
+
+	dir.Visit(VisitJump, 2)
+	dir.Visit(VisitCd, 1)
+	dir.Visit(VisitJump, 2)
+
+	if dir.Score != 6 {
+		t.Errorf("Expected Score to be 6, got %f", dir.Score)
+	}
+	if dir.Visits.Of(VisitJump) != 2 || dir.Visits.Of(VisitCd) != 1 || dir.Visits.Total() != 3 {
+		t.Errorf("Unexpected visit counts %+v", dir.Visits)
+	}
+	if dir.LastVisit == 0 {
+		t.Error("Expected LastVisit to be updated")
+	}
+}
 
-	originalScore := dir.Score
-	dir.UpdateScore()
+func TestParseVisitKind(t *testing.T) {
+	for i, name := range VisitKinds {
+		kind, err := ParseVisitKind(name)
+		if err != nil || kind != VisitKind(i) || kind.String() != name {
+			t.Errorf("ParseVisitKind(%q) = %v, %v", name, kind, err)
+		}
+	}
+	if _, err := ParseVisitKind("teleport"); err == nil {
+		t.Error("Expected an unknown kind to be rejected")
+	}
+}
+
+func TestVisitKeepsRecentVisits(t *testing.T) {
+	dir := NewDirectory("/path/to/directory")
+	for range RecentVisits + 5 {
+		dir.Visit(VisitCd, 1)
+	}
+	if len(dir.Recent) != RecentVisits {
+		t.Errorf("Expected %d recent visits, got %d", RecentVisits, len(dir.Recent))
+	}
+	if dir.LastJump != 0 {
+		t.Errorf("Expected no jump, got %d", dir.LastJump)
+	}
+	dir.Visit(VisitInteractive, 1)
+	if dir.LastJump != dir.LastVisit {
+		t.Errorf("Expected a pick to count as a jump")
+	}
+}
 
-	if dir.Score <= originalScore {
-		t.Errorf("Expected Score to be updated, got %f", dir.Score)
+func TestMerge(t *testing.T) {
+	a := &Directory{Path: "/p", Score: 1, LastVisit: 20, FirstSeen: 10, Recent: []Age{10, 20}, Visits: Visits{Cd: 2}}
+	b := &Directory{Path: "/p", Score: 2, LastVisit: 30, FirstSeen: 5, Recent: []Age{5, 30}, LastJump: 30, Source: SourceManual, Visits: Visits{Jump: 1}}
+	a.Merge(b)
+
+	if a.Score != 3 || a.LastVisit != 30 || a.FirstSeen != 5 || a.LastJump != 30 || a.Source != SourceManual {
+		t.Errorf("Unexpected merged entry %+v", a)
+	}
+	if a.Visits.Total() != 3 || len(a.Recent) != 4 || a.Recent[0] != 5 || a.Recent[3] != 30 {
+		t.Errorf("Unexpected merged visits %+v, %v", a.Visits, a.Recent)
+	}
+
+	a.Mark, b.Mark = "", "work"
+	if dropped := a.Merge(b); dropped != "" || a.Mark != "work" {
+		t.Errorf("expected the bookmark to be taken over, got %q (dropped %q)", a.Mark, dropped)
+	}
+	b.Mark = "api"
+	if dropped := a.Merge(b); dropped != "api" || a.Mark != "work" {
+		t.Errorf("expected the second bookmark to be reported as dropped, got %q (dropped %q)", a.Mark, dropped)
+	}
+}
+
+func TestTags(t *testing.T) {
+	dir := &Directory{Path: "/p"}
+	for _, tag := range []string{"web", "api", "web"} {
+		dir.AddTag(tag)
+	}
+	if !slices.Equal(dir.Tags, []string{"api", "web"}) {
+		t.Fatalf("Expected sorted unique tags, got %v", dir.Tags)
+	}
+	if !dir.HasTag("api") || dir.HasTag("cli") {
+		t.Errorf("Unexpected HasTag results for %v", dir.Tags)
+	}
+	if !dir.RemoveTag("api") || dir.RemoveTag("api") || dir.HasTag("api") {
+		t.Errorf("Expected api to be removed once, got %v", dir.Tags)
+	}
+
+	other := &Directory{Path: "/p", Tags: []string{"cli", "web"}}
+	dir.Merge(other)
+	if !slices.Equal(dir.Tags, []string{"cli", "web"}) {
+		t.Errorf("Expected merged tags, got %v", dir.Tags)
+	}
+}
+
+func TestAddMetadata(t *testing.T) {
+	dm := &DirectoryManager{Entries: []*Directory{{Path: "/p", Score: 3.2, LastVisit: 100}}}
+	if err := addMetadata(dm); err != nil {
+		t.Fatal(err)
+	}
+	dir := dm.Entries[0]
+	if dir.FirstSeen != 100 || len(dir.Recent) != 1 || dir.Visits.Cd != 3 || dir.Source != SourceUnknown {
+		t.Errorf("Unexpected migrated entry %+v", dir)
 	}
 }
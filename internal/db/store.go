@@ -27,16 +27,51 @@ type DataStore interface {
 	DetermineFilthy() error
 	SwapRemoveIDX(idx int) error  // Remove by index, O(1)
 	SwapRemove(path string) error // Remove by path, O(1) if found
+	TogglePin(path string) error
+	SetScore(path string, score Score) error
+	ScaleScore(path string, factor Score) (Score, error)
+	SetPinned(path string, pinned bool) error
+	Visit(path string, kind VisitKind, increment Score) *Directory
+	SetMark(path, name string) error
+	Unmark(name string) error
+	Marked(name string) (*Directory, error)
 }
 
 type DirectoryManager struct {
 	Entries  []*Directory
 	FilePath string
 	Dirty    bool
+	Version  int // schema version of the data, see SchemaVersion
 	raw      []byte
 	mu       sync.RWMutex
 }
 
+// SchemaVersion is the version of the data file format. Stores saved by an
+// older version are upgraded by Migrations when they are loaded. The upgrade
+// is one-way: older releases cannot read the migrated file, so the file as it
+// was is kept at BackupPath first.
+const SchemaVersion = 2
+
+// BackupPath returns where the data file at filePath is copied before it is
+// migrated from version.
+func BackupPath(filePath string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", filePath, version)
+}
+
+// Migrations upgrade a store loaded from an older schema: Migrations[v] takes
+// it from version v to v+1. They are registered by the packages owning the
+// logic, and a store stays at the version of the first missing migration.
+var Migrations = map[int]func(dm *DirectoryManager) error{
+	1: addMetadata,
+}
+
+// storeFile is what a data file holds. Files written before versioning hold
+// a bare []*Directory and are read as version 0.
+type storeFile struct {
+	Version int
+	Entries []*Directory
+}
+
 // NewDirectoryManager creates a new GobStore instance by accessing reading in data from the given filepath.
 func NewDirectoryManagerWithPath(filePath string) (*DirectoryManager, error) {
 	dm := &DirectoryManager{
@@ -56,9 +91,39 @@ func NewDirectoryManagerWithPath(filePath string) (*DirectoryManager, error) {
 		return nil, err
 	}
 
+	if err := dm.migrate(); err != nil {
+		return nil, err
+	}
 	return dm, nil
 }
 
+// migrate runs the registered migrations from dm.Version on and saves the
+// result, so that each runs once per store. The original file is copied to
+// BackupPath before it is overwritten.
+func (dm *DirectoryManager) migrate() error {
+	from := dm.Version
+	migrated := false
+	for dm.Version < SchemaVersion {
+		migration, ok := Migrations[dm.Version]
+		if !ok {
+			break
+		}
+		if err := migration(dm); err != nil {
+			return fmt.Errorf("migrating store from version %d: %w", dm.Version, err)
+		}
+		dm.Version++
+		migrated = true
+	}
+	if !migrated {
+		return nil
+	}
+	if err := os.WriteFile(BackupPath(dm.FilePath, from), dm.raw, 0o644); err != nil {
+		return fmt.Errorf("backing up store before migrating: %w", err)
+	}
+	dm.Dirty = true
+	return dm.Save()
+}
+
 func NewDirectoryManager() (*DirectoryManager, error) {
 	filePath := os.Getenv("GOZELLE_DATA_DIR")
 	if filePath == "" {
@@ -100,10 +165,19 @@ func (dm *DirectoryManager) Decode(data *[]byte) error {
 
 	if data == nil || len(*data) == 0 {
 		dm.Entries = []*Directory{}
+		dm.Version = SchemaVersion
 		// log.Println("[DEBUG] Decode: No data found, initializing empty directory manager.")
 		return nil
 	}
 
+	var file storeFile
+	if err := gob.NewDecoder(bytes.NewReader(*data)).Decode(&file); err == nil {
+		dm.Entries = file.Entries
+		dm.Version = file.Version
+		return nil
+	}
+
+	// fall back to the unversioned format
 	decoder := gob.NewDecoder(bytes.NewReader(*data))
 	var decodedEntries []*Directory
 	err := decoder.Decode(&decodedEntries)
@@ -112,17 +186,18 @@ func (dm *DirectoryManager) Decode(data *[]byte) error {
 		return fmt.Errorf("failed to decode data: %w", err)
 	}
 	dm.Entries = decodedEntries
+	dm.Version = 0
 	// log.Printf("[DEBUG] Decode: Decoded %d entries.", len(dm.Entries))
 	return nil
 }
 
-// Encode encodes the DirectoryManager's Entries field into a byte slice.
+// Encode encodes entries into a byte slice, tagged with the manager's Version.
 func (dm *DirectoryManager) Encode(entries []*Directory) ([]byte, error) {
 	// dm.mu.RLock() // Encode reads Entries, so if called concurrently, RLock is needed.
 	// defer dm.mu.RUnlock() // Assuming lock is managed by caller (e.g., saveInternal)
 	var buf bytes.Buffer
 	encoder := gob.NewEncoder(&buf)
-	err := encoder.Encode(entries)
+	err := encoder.Encode(storeFile{Version: dm.Version, Entries: entries})
 	if err != nil {
 		log.Printf("[ERROR] Encode: failed to encode Entries: %v", err)
 		return nil, fmt.Errorf("failed to encode Entries: %w", err)
@@ -147,6 +222,28 @@ func (dm *DirectoryManager) Add(path string) error {
 	return nil
 }
 
+// Visit records a visit of kind to path, adding an entry for it if there is
+// none, and returns the entry. A new entry's score is just increment.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) Visit(path string, kind VisitKind, increment Score) *Directory {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	dm.Dirty = true
+	for _, dir := range dm.Entries {
+		if dir.Path == path {
+			dir.Visit(kind, increment)
+			return dir
+		}
+	}
+	dir := NewDirectory(path)
+	dir.Score = 0
+	dir.Source = SourceOf(kind)
+	dir.Visit(kind, increment)
+	dm.Entries = append(dm.Entries, dir)
+	return dir
+}
+
 // AddAndSave adds a new directory and immediately saves the directory manager to disk.
 // This is the legacy behavior of Add prior to v0.2.0.
 // AddAndSave adds a new directory and immediately saves the directory manager to disk.
@@ -255,9 +352,8 @@ func (dm *DirectoryManager) Dedup() error {
 			lastAdded := newEntries[len(newEntries)-1]
 			current := dm.Entries[i]
 			if lastAdded.Path == current.Path {
-				lastAdded.Score += current.Score
-				if lastAdded.LastVisit < current.LastVisit {
-					lastAdded.LastVisit = current.LastVisit
+				if dropped := lastAdded.Merge(current); dropped != "" {
+					log.Printf("[WARN] Dedup: bookmark %q removed, merged into %q of the same directory %s", dropped, lastAdded.Mark, lastAdded.Path)
 				}
 				dm.Dirty = true // Mark dirty if merging happened
 			} else {
@@ -413,6 +509,128 @@ func (dm *DirectoryManager) SwapRemove(path string) error {
 	return fmt.Errorf("SwapRemove: inconsistency for path %s", path)
 }
 
+// TogglePin pins the directory at path if it is not pinned and unpins it otherwise.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) TogglePin(path string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, dir := range dm.Entries {
+		if dir.Path == path {
+			dir.Pinned = !dir.Pinned
+			dm.Dirty = true
+			return nil
+		}
+	}
+	return fmt.Errorf("directory not found: %s", path)
+}
+
+// SetScore sets the score of the directory at path.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) SetScore(path string, score Score) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, dir := range dm.Entries {
+		if dir.Path == path {
+			dir.Score = score
+			dm.Dirty = true
+			return nil
+		}
+	}
+	return fmt.Errorf("directory not found: %s", path)
+}
+
+// ScaleScore multiplies the score of the directory at path by factor and
+// returns the new score.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) ScaleScore(path string, factor Score) (Score, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, dir := range dm.Entries {
+		if dir.Path == path {
+			dir.Score *= factor
+			dm.Dirty = true
+			return dir.Score, nil
+		}
+	}
+	return 0, fmt.Errorf("directory not found: %s", path)
+}
+
+// SetPinned pins or unpins the directory at path.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) SetPinned(path string, pinned bool) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, dir := range dm.Entries {
+		if dir.Path == path {
+			if dir.Pinned != pinned {
+				dir.Pinned = pinned
+				dm.Dirty = true
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("directory not found: %s", path)
+}
+
+// SetMark makes name the bookmark of the directory at path, taking it from any
+// other entry and replacing the entry's previous bookmark.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) SetMark(path, name string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	var target *Directory
+	for _, dir := range dm.Entries {
+		if dir.Path == path {
+			target = dir
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("directory not found: %s", path)
+	}
+	for _, dir := range dm.Entries {
+		if dir.Mark == name && dir != target {
+			dir.Mark = ""
+		}
+	}
+	target.Mark = name
+	dm.Dirty = true
+	return nil
+}
+
+// Unmark removes the bookmark name.
+// Like Add it only changes memory; call Save() to persist.
+func (dm *DirectoryManager) Unmark(name string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, dir := range dm.Entries {
+		if dir.Mark == name {
+			dir.Mark = ""
+			dm.Dirty = true
+			return nil
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", name)
+}
+
+// Marked returns the directory the bookmark name resolves to.
+func (dm *DirectoryManager) Marked(name string) (*Directory, error) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	for _, dir := range dm.Entries {
+		if dir.Mark == name {
+			return dir, nil
+		}
+	}
+	return nil, fmt.Errorf("bookmark not found: %s", name)
+}
+
 func quickSort(arr []*Directory, low, high int) {
 	if low < high {
 		pi := partition(arr, low, high)
@@ -1,8 +1,11 @@
 package db
 
 import (
+	"bytes"
+	"encoding/gob"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -380,3 +383,186 @@ func TestRemove(t *testing.T) {
 // 		t.Fatal("expected dirty flag to be false")
 // 	}
 // }
+
+func TestTogglePin(t *testing.T) {
+	dm, err := CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.dummyData()
+
+	if err := dm.TogglePin("/test/path2"); err != nil {
+		t.Fatalf("failed to pin directory: %v", err)
+	}
+	if !dm.Entries[1].Pinned || !dm.Dirty {
+		t.Fatal("expected /test/path2 to be pinned and the store dirty")
+	}
+	dm.Save()
+
+	reloaded, err := NewDirectoryManagerWithPath(dm.FilePath)
+	if err != nil {
+		t.Fatalf("failed to reload store: %v", err)
+	}
+	entry, _ := reloaded.Get("/test/path2")
+	if !entry.Pinned {
+		t.Fatal("expected pin to be persisted")
+	}
+
+	dm.TogglePin("/test/path2")
+	if dm.Entries[1].Pinned {
+		t.Fatal("expected /test/path2 to be unpinned")
+	}
+
+	if err := dm.TogglePin("/missing"); err == nil {
+		t.Fatal("expected error pinning a missing directory")
+	}
+}
+
+func TestSetScore(t *testing.T) {
+	dm, err := CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.dummyData()
+
+	if err := dm.SetScore("/test/path3", 7.5); err != nil {
+		t.Fatalf("failed to set score: %v", err)
+	}
+	if dm.Entries[2].Score != 7.5 {
+		t.Fatalf("expected score 7.5, got %f", dm.Entries[2].Score)
+	}
+	if err := dm.SetScore("/missing", 1); err == nil {
+		t.Fatal("expected error setting the score of a missing directory")
+	}
+}
+
+func TestScaleScore(t *testing.T) {
+	dm, err := CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.dummyData()
+
+	if score, err := dm.ScaleScore("/test/path2", 3); err != nil || score != 3*DefaultScore {
+		t.Fatalf("expected score %f, got %f (%v)", 3*DefaultScore, score, err)
+	}
+	if dm.Entries[1].Score != 3*DefaultScore {
+		t.Fatalf("expected the entry to be rescored, got %f", dm.Entries[1].Score)
+	}
+	if _, err := dm.ScaleScore("/missing", 2); err == nil {
+		t.Fatal("expected error scaling the score of a missing directory")
+	}
+}
+
+func TestSetPinned(t *testing.T) {
+	dm, err := CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.dummyData()
+
+	for _, pinned := range []bool{true, true, false} {
+		if err := dm.SetPinned("/test/path1", pinned); err != nil {
+			t.Fatalf("failed to set pinned: %v", err)
+		}
+		if dm.Entries[0].Pinned != pinned {
+			t.Fatalf("expected pinned %v, got %v", pinned, dm.Entries[0].Pinned)
+		}
+	}
+	if err := dm.SetPinned("/missing", true); err == nil {
+		t.Fatal("expected error pinning a missing directory")
+	}
+}
+
+func TestSetMark(t *testing.T) {
+	dm, err := CreateTestStore()
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	defer dm.DeleteTestStore()
+
+	dm.dummyData()
+
+	if err := dm.SetMark("/test/path1", "infra"); err != nil {
+		t.Fatalf("failed to set mark: %v", err)
+	}
+	if err := dm.SetMark("/test/path2", "infra"); err != nil {
+		t.Fatalf("failed to move mark: %v", err)
+	}
+	if dir, err := dm.Marked("infra"); err != nil || dir.Path != "/test/path2" {
+		t.Fatalf("expected infra to resolve to /test/path2, got %v, %v", dir, err)
+	}
+	if dm.Entries[0].Mark != "" {
+		t.Fatalf("expected the mark to move off /test/path1, got %q", dm.Entries[0].Mark)
+	}
+	if err := dm.SetMark("/missing", "x"); err == nil {
+		t.Fatal("expected error marking a missing directory")
+	}
+
+	if err := dm.Unmark("infra"); err != nil {
+		t.Fatalf("failed to unmark: %v", err)
+	}
+	if _, err := dm.Marked("infra"); err == nil {
+		t.Fatal("expected infra to be gone")
+	}
+	if err := dm.Unmark("infra"); err == nil {
+		t.Fatal("expected error removing a missing mark")
+	}
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode([]*Directory{NewDirectory("/legacy")}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	dm := &DirectoryManager{}
+	if err := dm.Decode(&data); err != nil {
+		t.Fatalf("failed to decode unversioned data: %v", err)
+	}
+	if dm.Version != 0 || len(dm.Entries) != 1 || dm.Entries[0].Path != "/legacy" {
+		t.Fatalf("unexpected decoded store: version %d, entries %v", dm.Version, dm.Entries)
+	}
+
+	dm.Version = SchemaVersion
+	encoded, err := dm.Encode(dm.Entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &DirectoryManager{}
+	if err := decoded.Decode(&encoded); err != nil || decoded.Version != SchemaVersion {
+		t.Fatalf("expected version %d after a round trip, got %d (%v)", SchemaVersion, decoded.Version, err)
+	}
+}
+
+func TestMigrateBackup(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(storeFile{Version: 1, Entries: []*Directory{{Path: "/old", Score: 3, LastVisit: 100}}}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "db.gob")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dm, err := NewDirectoryManagerWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm.Version != SchemaVersion {
+		t.Fatalf("expected the store to be migrated to version %d, got %d", SchemaVersion, dm.Version)
+	}
+	backup, err := os.ReadFile(BackupPath(path, 1))
+	if err != nil || !bytes.Equal(backup, buf.Bytes()) {
+		t.Fatalf("expected the original file to be backed up, got %v", err)
+	}
+}
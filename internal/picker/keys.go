@@ -0,0 +1,104 @@
+package picker
+
+import "unicode/utf8"
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyBackspace
+	keyClear
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyTab
+	keyEnter
+	keyCancel
+	keyAlt // Alt+r, sent by terminals as ESC r
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// decodeKeys turns a chunk of raw terminal input into keys.
+// A lone ESC at the end of a chunk is treated as the Esc key, otherwise it
+// starts an escape sequence; unknown sequences and control bytes are ignored.
+func decodeKeys(buf []byte) []key {
+	var keys []key
+	for i := 0; i < len(buf); {
+		b := buf[i]
+		switch {
+		case b == '\r' || b == '\n':
+			keys = append(keys, key{kind: keyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+		case b == '\t':
+			keys = append(keys, key{kind: keyTab})
+		case b == 0x03 || b == 0x07: // Ctrl-C, Ctrl-G
+			keys = append(keys, key{kind: keyCancel})
+		case b == 0x15: // Ctrl-U
+			keys = append(keys, key{kind: keyClear})
+		case b == 0x10 || b == 0x0b: // Ctrl-P, Ctrl-K
+			keys = append(keys, key{kind: keyUp})
+		case b == 0x0e: // Ctrl-N
+			keys = append(keys, key{kind: keyDown})
+		case b == 0x1b:
+			k, n := decodeEscape(buf[i:])
+			if k != nil {
+				keys = append(keys, *k)
+			}
+			i += n
+			continue
+		case b < 0x20:
+			// other control characters are ignored
+		default:
+			r, size := utf8.DecodeRune(buf[i:])
+			if r != utf8.RuneError {
+				keys = append(keys, key{kind: keyRune, r: r})
+			}
+			i += size
+			continue
+		}
+		i++
+	}
+	return keys
+}
+
+// decodeEscape decodes the escape sequence at the start of buf and returns the
+// key (nil if unknown) and the number of bytes consumed.
+func decodeEscape(buf []byte) (*key, int) {
+	if len(buf) == 1 || buf[1] == 0x1b {
+		return &key{kind: keyCancel}, 1
+	}
+	if buf[1] != '[' && buf[1] != 'O' {
+		if buf[1] >= 0x20 && buf[1] < 0x7f {
+			return &key{kind: keyAlt, r: rune(buf[1])}, 2
+		}
+		return &key{kind: keyCancel}, 1
+	}
+	// CSI/SS3: parameters followed by a final byte in 0x40-0x7e
+	end := 2
+	for end < len(buf) && (buf[end] < 0x40 || buf[end] > 0x7e) {
+		end++
+	}
+	if end == len(buf) {
+		return nil, len(buf)
+	}
+	seq := string(buf[2 : end+1])
+	switch seq {
+	case "A":
+		return &key{kind: keyUp}, end + 1
+	case "B":
+		return &key{kind: keyDown}, end + 1
+	case "5~":
+		return &key{kind: keyPageUp}, end + 1
+	case "6~":
+		return &key{kind: keyPageDown}, end + 1
+	case "Z": // Shift-Tab
+		return &key{kind: keyTab}, end + 1
+	}
+	return nil, end + 1
+}
@@ -0,0 +1,204 @@
+// Package picker implements gozelle's built-in terminal picker, used for
+// interactive mode when fzf is not installed or --picker builtin is given.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCancelled is returned when the user aborts the picker with Esc, Ctrl-C or Ctrl-G.
+var ErrCancelled = errors.New("selection cancelled")
+
+// Item is a single candidate. Value is returned when the item is picked,
+// Label is what gets displayed and matched against.
+type Item struct {
+	Value string
+	Label string
+}
+
+// Options configures the picker.
+type Options struct {
+	Multi  bool   // allow selecting several items with Tab
+	Query  string // initial query
+	Prompt string // defaults to "> "
+	// Match reports whether value matches the keywords typed so far.
+	// The query is split on whitespace into keywords; an empty query matches everything.
+	Match func(value string, keywords []string) bool
+	// Actions are bound to Alt+Key and run on the selected items, or the highlighted one.
+	Actions []Action
+}
+
+// Action is a key binding that changes the underlying data. Run receives the
+// values it applies to and returns the refreshed items to display.
+type Action struct {
+	Key   rune
+	Label string
+	Run   func(values []string) ([]Item, error)
+}
+
+// state holds everything the picker needs to render and react to input.
+// It is kept free of terminal concerns so key handling can be tested directly.
+type state struct {
+	items    []Item
+	opts     Options
+	query    []rune
+	matches  []int // indexes into items, in the original (frecency) order
+	cursor   int   // index into matches
+	offset   int   // first visible match
+	selected map[int]bool
+	done     bool
+	err      error
+	status   string // result of the last action, shown next to the counter
+}
+
+func newState(items []Item, opts Options) *state {
+	if opts.Prompt == "" {
+		opts.Prompt = "> "
+	}
+	s := &state{
+		items:    items,
+		opts:     opts,
+		query:    []rune(opts.Query),
+		selected: map[int]bool{},
+	}
+	s.filter()
+	return s
+}
+
+// filter recomputes the matches for the current query, keeping the original order.
+func (s *state) filter() {
+	keywords := strings.Fields(string(s.query))
+	s.matches = s.matches[:0]
+	for i, item := range s.items {
+		if len(keywords) == 0 || s.opts.Match == nil || s.opts.Match(item.Value, keywords) {
+			s.matches = append(s.matches, i)
+		}
+	}
+	s.cursor = 0
+	s.offset = 0
+}
+
+func (s *state) move(delta int) {
+	if len(s.matches) == 0 {
+		return
+	}
+	s.cursor += delta
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	if s.cursor >= len(s.matches) {
+		s.cursor = len(s.matches) - 1
+	}
+}
+
+// scroll keeps the cursor within a window of height rows.
+func (s *state) scroll(height int) {
+	if height < 1 {
+		height = 1
+	}
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+height {
+		s.offset = s.cursor - height + 1
+	}
+}
+
+func (s *state) toggle() {
+	if !s.opts.Multi || len(s.matches) == 0 {
+		return
+	}
+	idx := s.matches[s.cursor]
+	if s.selected[idx] {
+		delete(s.selected, idx)
+	} else {
+		s.selected[idx] = true
+	}
+	s.move(1)
+}
+
+// runAction runs the action bound to Alt plus r and replaces the items with its result.
+func (s *state) runAction(r rune) {
+	for _, a := range s.opts.Actions {
+		if a.Key != r {
+			continue
+		}
+		values := s.result()
+		if len(values) == 0 {
+			return
+		}
+		items, err := a.Run(values)
+		if err != nil {
+			s.status = err.Error()
+			return
+		}
+		s.status = fmt.Sprintf("%s: %d", a.Label, len(values))
+		cursor := s.cursor
+		s.items = items
+		s.selected = map[int]bool{}
+		s.filter()
+		s.move(cursor)
+		return
+	}
+}
+
+func (s *state) accept() {
+	s.done = true
+	if len(s.selected) == 0 && len(s.matches) == 0 {
+		s.err = errors.New("no matching directory")
+	}
+}
+
+// result returns the picked values: the multi-selection in display order if
+// there is one, otherwise the item under the cursor.
+func (s *state) result() []string {
+	var values []string
+	if len(s.selected) > 0 {
+		for i, item := range s.items {
+			if s.selected[i] {
+				values = append(values, item.Value)
+			}
+		}
+		return values
+	}
+	if len(s.matches) == 0 {
+		return nil
+	}
+	return []string{s.items[s.matches[s.cursor]].Value}
+}
+
+// handle applies a single key to the state.
+func (s *state) handle(k key) {
+	switch k.kind {
+	case keyRune:
+		s.query = append(s.query, k.r)
+		s.filter()
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.filter()
+		}
+	case keyClear:
+		s.query = s.query[:0]
+		s.filter()
+	case keyUp:
+		s.move(-1)
+	case keyDown:
+		s.move(1)
+	case keyPageUp:
+		s.move(-10)
+	case keyPageDown:
+		s.move(10)
+	case keyTab:
+		s.toggle()
+	case keyEnter:
+		s.accept()
+	case keyAlt:
+		s.runAction(k.r)
+	case keyCancel:
+		s.done = true
+		s.err = ErrCancelled
+	}
+}
@@ -0,0 +1,182 @@
+package picker
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+func testItems() []Item {
+	paths := []string{"/home/user/projects/api", "/home/user/projects/web", "/srv/api", "/tmp"}
+	items := make([]Item, len(paths))
+	for i, p := range paths {
+		items[i] = Item{Value: p, Label: p}
+	}
+	return items
+}
+
+func containsMatch(value string, keywords []string) bool {
+	for _, k := range keywords {
+		if !strings.Contains(value, k) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDecodeKeys(t *testing.T) {
+	keys := decodeKeys([]byte("a\x1b[B\x1b[A\t\x7f\r\x1b"))
+	want := []keyKind{keyRune, keyDown, keyUp, keyTab, keyBackspace, keyEnter, keyCancel}
+	if len(keys) != len(want) {
+		t.Fatalf("expected %d keys, got %d: %v", len(want), len(keys), keys)
+	}
+	for i, k := range keys {
+		if k.kind != want[i] {
+			t.Fatalf("key %d: expected kind %d, got %d", i, want[i], k.kind)
+		}
+	}
+	if keys[0].r != 'a' {
+		t.Fatalf("expected rune a, got %q", keys[0].r)
+	}
+
+	if keys := decodeKeys([]byte("é")); len(keys) != 1 || keys[0].r != 'é' {
+		t.Fatalf("expected a single é rune, got %v", keys)
+	}
+}
+
+func TestStateFilterAndNavigate(t *testing.T) {
+	s := newState(testItems(), Options{Match: containsMatch})
+	if len(s.matches) != 4 {
+		t.Fatalf("expected all 4 items to match an empty query, got %d", len(s.matches))
+	}
+
+	for _, r := range "api" {
+		s.handle(key{kind: keyRune, r: r})
+	}
+	if len(s.matches) != 2 {
+		t.Fatalf("expected 2 matches for api, got %d", len(s.matches))
+	}
+
+	s.handle(key{kind: keyDown})
+	s.handle(key{kind: keyDown}) // clamped at the last match
+	s.handle(key{kind: keyEnter})
+	if !s.done || s.err != nil {
+		t.Fatalf("expected picker to be done without error, got %v", s.err)
+	}
+	if got := s.result(); !reflect.DeepEqual(got, []string{"/srv/api"}) {
+		t.Fatalf("unexpected result %v", got)
+	}
+}
+
+func TestStateMultiSelect(t *testing.T) {
+	s := newState(testItems(), Options{Multi: true, Match: containsMatch})
+	s.handle(key{kind: keyTab})  // select the first item, move down
+	s.handle(key{kind: keyDown}) // skip the second
+	s.handle(key{kind: keyTab})  // select the third
+	s.handle(key{kind: keyEnter})
+
+	want := []string{"/home/user/projects/api", "/srv/api"}
+	if got := s.result(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestStateCancel(t *testing.T) {
+	s := newState(testItems(), Options{})
+	s.handle(key{kind: keyCancel})
+	if s.err != ErrCancelled {
+		t.Fatalf("expected ErrCancelled, got %v", s.err)
+	}
+}
+
+// runScripted drives Run through a pseudo-terminal, typing each chunk of input in turn.
+func runScripted(t *testing.T, items []Item, opts Options, input ...string) ([]string, error) {
+	t.Helper()
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		t.Skipf("pseudo-terminals unavailable: %v", err)
+	}
+	defer ptmx.Close()
+	defer tty.Close()
+	pty.Setsize(ptmx, &pty.Winsize{Rows: 10, Cols: 60})
+
+	// the picker blocks on writes if nobody reads its output
+	go io.Copy(io.Discard, ptmx)
+
+	type result struct {
+		values []string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := Run(tty, items, opts)
+		done <- result{values, err}
+	}()
+
+	for _, chunk := range input {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := ptmx.Write([]byte(chunk)); err != nil {
+			t.Fatalf("failed to write to pty: %v", err)
+		}
+	}
+
+	select {
+	case r := <-done:
+		return r.values, r.err
+	case <-time.After(5 * time.Second):
+		t.Fatal("picker did not finish")
+		return nil, nil
+	}
+}
+
+func TestRunThroughPty(t *testing.T) {
+	got, err := runScripted(t, testItems(), Options{Match: containsMatch}, "web", "\r")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"/home/user/projects/web"}) {
+		t.Fatalf("unexpected selection %v", got)
+	}
+
+	got, err = runScripted(t, testItems(), Options{Multi: true, Match: containsMatch}, "\t", "\x1b[B", "\t", "\x1b[A", "\r")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"/home/user/projects/api", "/srv/api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	_, err = runScripted(t, testItems(), Options{}, "\x03")
+	if err != ErrCancelled {
+		t.Fatalf("expected ErrCancelled, got %v", err)
+	}
+}
+
+func TestStateAction(t *testing.T) {
+	var got []string
+	remove := Action{Key: 'd', Label: "delete", Run: func(values []string) ([]Item, error) {
+		got = values
+		return testItems()[2:], nil
+	}}
+	s := newState(testItems(), Options{Multi: true, Actions: []Action{remove}})
+	s.handle(key{kind: keyTab})
+	s.handle(key{kind: keyTab})
+	for _, k := range decodeKeys([]byte("\x1bd")) {
+		s.handle(k)
+	}
+
+	if !reflect.DeepEqual(got, []string{"/home/user/projects/api", "/home/user/projects/web"}) {
+		t.Fatalf("expected action to receive the selection, got %v", got)
+	}
+	if len(s.items) != 2 || len(s.selected) != 0 {
+		t.Fatalf("expected items to be reloaded and the selection cleared, got %d items, %d selected", len(s.items), len(s.selected))
+	}
+	if s.done {
+		t.Fatal("expected picker to keep running after an action")
+	}
+}
@@ -0,0 +1,59 @@
+package picker
+
+import (
+	"fmt"
+	"io"
+)
+
+// render draws the prompt, a match counter and as many matches as fit.
+func (s *state) render(w io.Writer, width, height int) {
+	listHeight := height - 2
+	s.scroll(listHeight)
+
+	fmt.Fprint(w, "\x1b[H")
+	fmt.Fprintf(w, "%s%s\x1b[K\r\n", s.opts.Prompt, string(s.query))
+	counter := fmt.Sprintf("  %d/%d", len(s.matches), len(s.items))
+	if len(s.selected) > 0 {
+		counter += fmt.Sprintf(" (%d selected)", len(s.selected))
+	}
+	for _, a := range s.opts.Actions {
+		counter += fmt.Sprintf("  alt-%c: %s", a.Key, a.Label)
+	}
+	if s.status != "" {
+		counter += "  [" + s.status + "]"
+	}
+	counter = truncate(counter, width)
+	fmt.Fprintf(w, "\x1b[2m%s\x1b[0m\x1b[K", counter)
+
+	for row := 0; row < listHeight && s.offset+row < len(s.matches); row++ {
+		i := s.offset + row
+		idx := s.matches[i]
+		cursor, mark := "  ", " "
+		if i == s.cursor {
+			cursor = "> "
+		}
+		if s.selected[idx] {
+			mark = "*"
+		}
+		line := truncate(cursor+mark+" "+s.items[idx].Label, width)
+		if i == s.cursor {
+			fmt.Fprintf(w, "\r\n\x1b[1m%s\x1b[0m\x1b[K", line)
+		} else {
+			fmt.Fprintf(w, "\r\n%s\x1b[K", line)
+		}
+	}
+	fmt.Fprint(w, "\x1b[J")
+	// leave the cursor at the end of the query
+	fmt.Fprintf(w, "\x1b[1;%dH", len([]rune(s.opts.Prompt))+len(s.query)+1)
+}
+
+func truncate(line string, width int) string {
+	runes := []rune(line)
+	if width <= 0 || len(runes) <= width {
+		return line
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,61 @@
+package picker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// OpenTTY opens the controlling terminal. The picker draws on the terminal
+// rather than stdout so the selection can be captured with $(...).
+func OpenTTY() (*os.File, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open terminal: %w", err)
+	}
+	return tty, nil
+}
+
+// Run shows items on tty and lets the user pick from them.
+// It puts the terminal in raw mode and uses the alternate screen, restoring both on return.
+func Run(tty *os.File, items []Item, opts Options) ([]string, error) {
+	fd := int(tty.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	fmt.Fprint(tty, "\x1b[?1049h")
+	defer fmt.Fprint(tty, "\x1b[?1049l")
+
+	s := newState(items, opts)
+	out := bufio.NewWriter(tty)
+	buf := make([]byte, 256)
+	for {
+		width, height, err := term.GetSize(fd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		s.render(out, width, height)
+		if err := out.Flush(); err != nil {
+			return nil, err
+		}
+
+		n, err := tty.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from terminal: %w", err)
+		}
+		for _, k := range decodeKeys(buf[:n]) {
+			s.handle(k)
+			if s.done {
+				if s.err != nil {
+					return nil, s.err
+				}
+				return s.result(), nil
+			}
+		}
+	}
+}
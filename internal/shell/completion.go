@@ -0,0 +1,9 @@
+package shell
+
+// Completion returns the script registering tab completion for the jump and
+// interactive functions. Keywords complete to the best stored paths for the
+// words typed so far, as listed by `gozelle query --list`; words that look like
+// paths are completed as directories.
+func Completion(shell string, opts Options) (string, error) {
+	return execute(shell, "completion.tmpl", opts)
+}
@@ -0,0 +1,60 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	for _, sh := range []string{"bash", "zsh", "fish"} {
+		script, err := Completion(sh, Options{Jump: "gz", Interactive: "gi"})
+		if err != nil {
+			t.Fatalf("Completion(%q) returned error: %v", sh, err)
+		}
+		if !strings.Contains(script, "gozelle query --list") {
+			t.Fatalf("expected %s completion to list matches, got:\n%s", sh, script)
+		}
+	}
+	if _, err := Completion("tcsh", Options{}); err == nil {
+		t.Fatal("expected error for unsupported shell")
+	}
+}
+
+// TestBashCompletion runs the bash completion function against a fake gozelle
+// that echoes the keywords it receives.
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	dir := t.TempDir()
+	fake := "#!/bin/sh\nshift 5\necho \"/match/$*\"\necho '/with space'\n"
+	if err := os.WriteFile(filepath.Join(dir, "gozelle"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	script, _ := Completion("bash", Options{Jump: "gz", Interactive: "gi"})
+	script += `
+COMP_WORDS=(gz foo ba)
+COMP_CWORD=2
+__gozelle_complete
+printf '[%s]\n' "${COMPREPLY[@]}"
+COMP_WORDS=(gz ./sr)
+COMP_CWORD=1
+__gozelle_complete
+echo "paths: ${#COMPREPLY[@]}"
+`
+	cmd := exec.Command(bash, "--norc", "-c", script)
+	cmd.Env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash completion failed: %v\n%s", err, out)
+	}
+	want := "[/match/foo\\ ba]\n[/with\\ space]\npaths: 0\n"
+	if string(out) != want {
+		t.Fatalf("unexpected completions:\n%s\nexpected:\n%s", out, want)
+	}
+}
@@ -1 +1,57 @@
+// Package shell holds the pieces of shell integration emitted by `gozelle init`.
 package shell
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Hook modes select when the shell records visited directories.
+const (
+	HookNone   = "none"   // never, directories are only added with gozelle add
+	HookPrompt = "prompt" // at every prompt
+	HookPwd    = "pwd"    // whenever the working directory changes
+)
+
+// Hooks lists the accepted hook modes.
+var Hooks = []string{HookNone, HookPrompt, HookPwd}
+
+// DefaultCmd is the name of the jump function; the interactive one is "gi".
+const DefaultCmd = "gz"
+
+// Options configures the init script.
+type Options struct {
+	Jump        string // name of the jump function, e.g. gz or cd
+	Interactive string // name of the interactive function, e.g. gi or cdi
+	Hook        string // one of Hooks
+	Key         string // key the widget is bound to, see KeySequence
+	Exe         string // gozelle executable the script calls, "gozelle" if empty
+}
+
+// NewOptions returns the options for --cmd cmd and --hook hook. An empty cmd
+// keeps gz and gi, any other name defines <cmd> and <cmd>i.
+func NewOptions(cmd, hook, key string) (Options, error) {
+	opts := Options{Jump: DefaultCmd, Interactive: "gi", Hook: hook, Key: key}
+	if cmd != "" {
+		if !validName.MatchString(cmd) {
+			return opts, fmt.Errorf("invalid command name %q", cmd)
+		}
+		opts.Jump, opts.Interactive = cmd, cmd+"i"
+	}
+	switch hook {
+	case HookNone, HookPrompt, HookPwd:
+	default:
+		return opts, fmt.Errorf("invalid hook %q (expected one of %s)", hook, strings.Join(Hooks, ", "))
+	}
+	return opts, nil
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Script returns the init script for shell: the hook, the jump and
+// interactive functions, the widget and their completion. The posix and ksh
+// scripts have neither a widget nor completion; the nushell one is a module.
+func Script(shell string, opts Options) (string, error) {
+	return execute(shell, "init.tmpl", opts)
+}
@@ -0,0 +1,307 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	opts, err := NewOptions("", HookPwd, DefaultWidgetKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Jump != "gz" || opts.Interactive != "gi" {
+		t.Fatalf("expected gz and gi by default, got %q and %q", opts.Jump, opts.Interactive)
+	}
+
+	opts, _ = NewOptions("cd", HookPwd, DefaultWidgetKey)
+	if opts.Jump != "cd" || opts.Interactive != "cdi" {
+		t.Fatalf("expected cd and cdi, got %q and %q", opts.Jump, opts.Interactive)
+	}
+
+	if _, err := NewOptions("rm -rf", HookPwd, ""); err == nil {
+		t.Fatal("expected error for an invalid command name")
+	}
+	if _, err := NewOptions("", "always", ""); err == nil {
+		t.Fatal("expected error for an invalid hook")
+	}
+}
+
+func TestScript(t *testing.T) {
+	for _, sh := range []string{"bash", "zsh", "fish"} {
+		opts, _ := NewOptions("j", HookNone, "none")
+		script, err := Script(sh, opts)
+		if err != nil {
+			t.Fatalf("Script(%q) returned error: %v", sh, err)
+		}
+		if strings.Contains(script, "gozelle add") {
+			t.Fatalf("expected no hook for %s with --hook none, got:\n%s", sh, script)
+		}
+		if strings.Contains(script, "gz") {
+			t.Fatalf("expected gz to be renamed for %s, got:\n%s", sh, script)
+		}
+		for _, name := range []string{"j", "ji"} {
+			if !strings.Contains(script, " "+name+"\n") && !strings.Contains(script, "\n"+name+"() {") {
+				t.Fatalf("expected %s to define %s, got:\n%s", sh, name, script)
+			}
+		}
+	}
+
+	opts, _ := NewOptions("", HookPrompt, DefaultWidgetKey)
+	script, _ := Script("zsh", opts)
+	if !strings.Contains(script, "add-zsh-hook precmd __gozelle_hook") {
+		t.Fatalf("expected a precmd hook in prompt mode, got:\n%s", script)
+	}
+	opts.Hook = HookPwd
+	script, _ = Script("zsh", opts)
+	if !strings.Contains(script, "add-zsh-hook chpwd __gozelle_hook") {
+		t.Fatalf("expected a chpwd hook in pwd mode, got:\n%s", script)
+	}
+
+	if _, err := Script("tcsh", opts); err == nil {
+		t.Fatal("expected error for unsupported shell")
+	}
+}
+
+// TestBashReplaceCd checks that --cmd cd works without recursing into itself.
+func TestBashReplaceCd(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+	opts, _ := NewOptions("cd", HookNone, "none")
+	script, _ := Script("bash", opts)
+	// cd -P must reach builtin cd rather than gozelle query
+	for _, run := range []string{"cd " + dir, "cd -P " + link} {
+		out, err := exec.Command(bash, "--norc", "-c", script+"\n"+run+" && pwd").CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: bash failed: %v\n%s", run, err, out)
+		}
+		if strings.TrimSpace(string(out)) != dir {
+			t.Fatalf("%s: expected to be in %s, got %q", run, dir, out)
+		}
+	}
+}
+
+// fakeGozelle stands in for the binary in the script tests: add logs the path,
+// its last argument, to $GOZELLE_LOG and query prints $GOZELLE_TARGET.
+const fakeGozelle = "#!/bin/sh\ncase $1 in\nadd) for arg; do path=$arg; done; echo \"$path\" >>\"$GOZELLE_LOG\" ;;\nquery) printf '%s' \"$GOZELLE_TARGET\" ;;\nesac\n"
+
+// TestPosixScript sources the posix script under dash with a fake gozelle that
+// logs the directories it is asked to add and answers queries with a fixed path.
+func TestPosixScript(t *testing.T) {
+	dash, err := exec.LookPath("dash")
+	if err != nil {
+		t.Skip("dash not installed")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target dir")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gozelle"), []byte(fakeGozelle), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		cmd, hook, run string
+		logged         bool
+	}{
+		{"", HookPwd, "gz targ && pwd", true},
+		{"", HookPwd, "cd \"$GOZELLE_TARGET\" && pwd", true},
+		{"", HookNone, "gz targ && pwd", false},
+		{"cd", HookPwd, "cd targ && pwd", true},
+		// -L goes to cd, which keeps the link in $PWD and records it
+		{"cd", HookPwd, "cd -L link && pwd -P && case $PWD in */link) ;; *) exit 3 ;; esac", false},
+		{"", HookPrompt, "gz targ && pwd && case $PS1 in *__gozelle_hook*) ;; *) exit 3 ;; esac", false},
+		{"", HookPwd, "gozelle incognito on && gz targ && pwd && case $PS1 in '(incognito) '*) ;; *) exit 3 ;; esac", false},
+		{"", HookPwd, "gozelle incognito on && gozelle incognito off && gz targ && pwd && case $PS1 in *incognito*) exit 3 ;; esac", true},
+	}
+	for _, c := range cases {
+		opts, _ := NewOptions(c.cmd, c.hook, "none")
+		script, err := Script("posix", opts)
+		if err != nil {
+			t.Fatalf("Script(posix) returned error: %v", err)
+		}
+		scriptPath := filepath.Join(dir, "init.sh")
+		if err := os.WriteFile(scriptPath, []byte(script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		logPath := filepath.Join(dir, "log")
+		os.Remove(logPath)
+
+		cmd := exec.Command(dash, "-c", ". \"$1\" && "+c.run, "dash", scriptPath)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"),
+			"GOZELLE_LOG="+logPath,
+			"GOZELLE_TARGET="+target,
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%+v: dash failed: %v\n%s", c, err, out)
+		}
+		if strings.TrimSpace(string(out)) != target {
+			t.Fatalf("%+v: expected to be in %s, got %q", c, target, out)
+		}
+		logged, _ := os.ReadFile(logPath)
+		if c.logged != (strings.TrimSpace(string(logged)) == target) {
+			t.Fatalf("%+v: unexpected recorded visits %q", c, logged)
+		}
+	}
+}
+
+func TestNushellScript(t *testing.T) {
+	opts, _ := NewOptions("cd", HookPwd, DefaultWidgetKey)
+	script, err := Script("nushell", opts)
+	if err != nil {
+		t.Fatalf("Script(nushell) returned error: %v", err)
+	}
+	for _, want := range []string{
+		"export alias cd = __gozelle_jump",
+		"export alias cdi = __gozelle_interactive",
+		"hooks.env_change.PWD",
+		"modifier: control keycode: char_g",
+		"gozelle query --list",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected nushell script to contain %q, got:\n%s", want, script)
+		}
+	}
+
+	opts, _ = NewOptions("", HookPrompt, "none")
+	script, _ = Script("nushell", opts)
+	if !strings.Contains(script, "pre_prompt") || strings.Contains(script, "env_change") {
+		t.Fatalf("expected a pre_prompt hook in prompt mode, got:\n%s", script)
+	}
+	if strings.Contains(script, "keybindings") {
+		t.Fatalf("expected no keybinding with key none, got:\n%s", script)
+	}
+
+	nu, err := exec.LookPath("nu")
+	if err != nil {
+		t.Skip("nu not installed")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gozelle.nu")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(nu, "--no-config-file", "-c", "use "+path+" *; gz "+dir+"; $env.PWD").CombinedOutput()
+	if err != nil {
+		t.Fatalf("nu failed: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(string(out)) != dir {
+		t.Fatalf("expected to be in %s, got %q", dir, out)
+	}
+}
+
+// TestPowershellScript runs the script with pwsh against a fake gozelle that
+// logs the directories it is asked to add and answers queries with a fixed path.
+func TestPowershellScript(t *testing.T) {
+	opts, _ := NewOptions("cd", HookPwd, "alt-j")
+	script, err := Script("powershell", opts)
+	if err != nil {
+		t.Fatalf("Script(powershell) returned error: %v", err)
+	}
+	for _, want := range []string{
+		"Set-Alias -Name cd -Value __gozelle_jump",
+		"Set-Alias -Name cdi -Value __gozelle_interactive",
+		"Set-PSReadLineKeyHandler -Chord 'Alt+j'",
+		"Register-ArgumentCompleter -CommandName __gozelle_jump, __gozelle_interactive, cd, cdi",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected powershell script to contain %q, got:\n%s", want, script)
+		}
+	}
+
+	pwsh, err := exec.LookPath("pwsh")
+	if err != nil {
+		t.Skip("pwsh not installed")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target dir")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gozelle"), []byte(fakeGozelle), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	opts, _ = NewOptions("", HookPwd, "none")
+	script, _ = Script("powershell", opts)
+	scriptPath := filepath.Join(dir, "init.ps1")
+	if err := os.WriteFile(scriptPath, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(dir, "log")
+
+	cmd := exec.Command(pwsh, "-NoProfile", "-NonInteractive", "-Command",
+		". '"+scriptPath+"'; gz targ; $null = prompt; (Get-Location).ProviderPath")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"GOZELLE_LOG="+logPath,
+		"GOZELLE_TARGET="+target,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("pwsh failed: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(string(out)) != target {
+		t.Fatalf("expected to be in %s, got %q", target, out)
+	}
+	logged, _ := os.ReadFile(logPath)
+	if strings.TrimSpace(string(logged)) != target {
+		t.Fatalf("expected the prompt hook to record %s, got %q", target, logged)
+	}
+}
+
+func TestTemplateOverride(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	dir := filepath.Join(configHome, "gozelle", "templates", "bash")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	override := "\n# custom widget for {{.Jump}} calling {{.Exe}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "widget.tmpl"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, _ := NewOptions("j", HookPwd, DefaultWidgetKey)
+	opts.Exe = "/opt/my tools/gozelle"
+	script, err := Script("bash", opts)
+	if err != nil {
+		t.Fatalf("Script returned error: %v", err)
+	}
+	if !strings.Contains(script, "# custom widget for j calling '/opt/my tools/gozelle'") {
+		t.Fatalf("expected the overridden widget, got:\n%s", script)
+	}
+	if strings.Contains(script, "__gozelle_widget") {
+		t.Fatalf("expected the built-in widget to be replaced, got:\n%s", script)
+	}
+	if !strings.Contains(script, "command '/opt/my tools/gozelle' query") {
+		t.Fatalf("expected the built-in templates to call the quoted executable, got:\n%s", script)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "widget.tmpl"), []byte("{{.Nope"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Script("bash", opts); err == nil || !strings.Contains(err.Error(), "widget.tmpl") {
+		t.Fatalf("expected a parse error naming the override, got %v", err)
+	}
+}
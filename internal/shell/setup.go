@@ -0,0 +1,177 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The setup block is delimited by these markers so that it can be found,
+// updated and removed again.
+const (
+	blockStart = "# >>> gozelle init >>>"
+	blockEnd   = "# <<< gozelle init <<<"
+)
+
+// SetupShells lists the shells gozelle setup can configure.
+var SetupShells = []string{"bash", "zsh", "fish", "ksh", "mksh", "posix", "powershell"}
+
+// DetectShell maps a $SHELL value such as /usr/bin/zsh to a shell name in
+// SetupShells.
+func DetectShell(shellEnv string) (string, error) {
+	if shellEnv == "" {
+		return "", errors.New("$SHELL is not set, pass the shell explicitly")
+	}
+	name := strings.TrimSuffix(filepath.Base(shellEnv), ".exe")
+	switch name {
+	case "bash", "zsh", "fish", "mksh":
+		return name, nil
+	case "ksh", "ksh93":
+		return "ksh", nil
+	case "sh", "dash", "ash", "pdksh":
+		return "posix", nil
+	case "pwsh", "powershell":
+		return "powershell", nil
+	case "nu", "nushell":
+		return "", errors.New("nushell is not set up automatically, see gozelle init nushell --help")
+	}
+	return "", fmt.Errorf("unsupported shell %q", name)
+}
+
+// RcFile returns the startup file gozelle setup edits for shell. The ksh
+// family and POSIX shells read the file named by $ENV when it is set; without
+// it ksh93 falls back to ~/.kshrc and mksh to ~/.mkshrc, while other POSIX
+// shells read nothing and only login shells read ~/.profile (see ProfileOnly).
+func RcFile(shell, home string) (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		return filepath.Join(configHome, "fish", "config.fish"), nil
+	case "ksh", "mksh", "posix":
+		if env := os.Getenv("ENV"); filepath.IsAbs(env) {
+			return env, nil
+		}
+		switch shell {
+		case "ksh":
+			return filepath.Join(home, ".kshrc"), nil
+		case "mksh":
+			return filepath.Join(home, ".mkshrc"), nil
+		}
+		return filepath.Join(home, ".profile"), nil
+	case "powershell":
+		return filepath.Join(configHome, "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	}
+	return "", fmt.Errorf("unsupported shell: %s", shell)
+}
+
+// ProfileOnly reports whether RcFile falls back to ~/.profile for shell, which
+// interactive shells that are not login shells do not read.
+func ProfileOnly(shell string) bool {
+	return shell == "posix" && !filepath.IsAbs(os.Getenv("ENV"))
+}
+
+// SetupBlock returns the marked block loading `gozelle init <shell> <args>`.
+// mksh loads the ksh script.
+func SetupBlock(shell string, args []string) (string, error) {
+	initShell := shell
+	if shell == "mksh" {
+		initShell = "ksh"
+	}
+	command := strings.Join(append([]string{"gozelle", "init", initShell}, args...), " ")
+	var line string
+	switch shell {
+	case "bash", "zsh", "ksh", "mksh", "posix":
+		line = fmt.Sprintf(`eval "$(%s)"`, command)
+	case "fish":
+		line = command + " | source"
+	case "powershell":
+		line = fmt.Sprintf("Invoke-Expression (& { (%s | Out-String) })", command)
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+	return blockStart + "\n" + line + "\n" + blockEnd + "\n", nil
+}
+
+// InsertBlock returns content with block appended, or replacing an existing
+// gozelle block. changed is false when content already holds block.
+func InsertBlock(content, block string) (updated string, changed bool) {
+	if start, end, ok := findBlock(content); ok {
+		if content[start:end] == block {
+			return content, false
+		}
+		return content[:start] + block + content[end:], true
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block, true
+}
+
+// RemoveBlock returns content without the gozelle block, and whether there was one.
+func RemoveBlock(content string) (updated string, removed bool) {
+	start, end, ok := findBlock(content)
+	if !ok {
+		return content, false
+	}
+	// drop the blank line InsertBlock put before the block
+	before := content[:start]
+	if strings.HasSuffix(before, "\n\n") {
+		before = before[:len(before)-1]
+	}
+	return before + content[end:], true
+}
+
+// findBlock locates the block including its trailing newline.
+func findBlock(content string) (start, end int, ok bool) {
+	start = strings.Index(content, blockStart)
+	if start == -1 {
+		return 0, 0, false
+	}
+	n := strings.Index(content[start:], blockEnd)
+	if n == -1 {
+		return 0, 0, false
+	}
+	end = start + n + len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// UpdateFile rewrites path with edit applied to its content, creating it and
+// its directory if needed. Unless dryRun is set the file is only written when
+// edit reports a change; the new content is returned either way.
+func UpdateFile(path string, dryRun bool, edit func(string) (string, bool)) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", false, err
+	}
+	updated, changed := edit(string(data))
+	if !changed || dryRun {
+		return updated, changed, nil
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", false, err
+	}
+	return updated, true, os.WriteFile(path, []byte(updated), mode)
+}
@@ -0,0 +1,138 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectShell(t *testing.T) {
+	cases := map[string]string{
+		"/bin/bash":           "bash",
+		"/usr/bin/zsh":        "zsh",
+		"/usr/local/bin/fish": "fish",
+		"/bin/ksh93":          "ksh",
+		"/bin/mksh":           "mksh",
+		"/bin/dash":           "posix",
+		"/usr/bin/pwsh":       "powershell",
+	}
+	for in, want := range cases {
+		got, err := DetectShell(in)
+		if err != nil || got != want {
+			t.Fatalf("DetectShell(%q) = %q, %v; expected %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "/bin/tcsh", "/usr/bin/nu"} {
+		if _, err := DetectShell(in); err == nil {
+			t.Fatalf("expected DetectShell(%q) to fail", in)
+		}
+	}
+}
+
+func TestRcFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("ENV", "")
+	cases := map[string]string{
+		"ksh":   ".kshrc",
+		"mksh":  ".mkshrc",
+		"posix": ".profile",
+	}
+	for shell, want := range cases {
+		got, err := RcFile(shell, home)
+		if err != nil || got != filepath.Join(home, want) {
+			t.Fatalf("RcFile(%q) = %q, %v; expected ~/%s", shell, got, err, want)
+		}
+	}
+	if !ProfileOnly("posix") {
+		t.Fatal("expected posix to fall back to ~/.profile without $ENV")
+	}
+
+	env := filepath.Join(home, ".shrc")
+	t.Setenv("ENV", env)
+	for shell := range cases {
+		if got, _ := RcFile(shell, home); got != env {
+			t.Fatalf("RcFile(%q) = %q; expected $ENV", shell, got)
+		}
+	}
+	if ProfileOnly("posix") {
+		t.Fatal("expected posix to use $ENV")
+	}
+}
+
+func TestSetupBlock(t *testing.T) {
+	block, err := SetupBlock("fish", []string{"--cmd", "j"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(block, "\ngozelle init fish --cmd j | source\n") {
+		t.Fatalf("unexpected fish block:\n%s", block)
+	}
+	block, _ = SetupBlock("bash", nil)
+	if !strings.Contains(block, "\neval \"$(gozelle init bash)\"\n") {
+		t.Fatalf("unexpected bash block:\n%s", block)
+	}
+	block, _ = SetupBlock("mksh", nil)
+	if !strings.Contains(block, "\neval \"$(gozelle init ksh)\"\n") {
+		t.Fatalf("unexpected mksh block:\n%s", block)
+	}
+}
+
+func TestInsertRemoveBlock(t *testing.T) {
+	original := "export EDITOR=vi"
+	block, _ := SetupBlock("bash", nil)
+
+	content, changed := InsertBlock(original, block)
+	if !changed || !strings.HasPrefix(content, original+"\n\n") || !strings.HasSuffix(content, block) {
+		t.Fatalf("unexpected content after insert:\n%s", content)
+	}
+	if again, changed := InsertBlock(content, block); changed || again != content {
+		t.Fatalf("expected inserting twice to be a no-op, got:\n%s", again)
+	}
+
+	other, _ := SetupBlock("bash", []string{"--hook", "prompt"})
+	replaced, changed := InsertBlock(content+"alias ll='ls -l'\n", other)
+	if !changed || strings.Count(replaced, blockStart) != 1 || !strings.Contains(replaced, "--hook prompt") || !strings.HasSuffix(replaced, "alias ll='ls -l'\n") {
+		t.Fatalf("expected the block to be replaced in place, got:\n%s", replaced)
+	}
+
+	removed, ok := RemoveBlock(content)
+	if !ok || removed != original+"\n" {
+		t.Fatalf("expected the block to be removed, got %q", removed)
+	}
+	if _, ok := RemoveBlock(removed); ok {
+		t.Fatal("expected nothing to remove")
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fish", "config.fish")
+	block, _ := SetupBlock("fish", nil)
+	insert := func(content string) (string, bool) { return InsertBlock(content, block) }
+
+	if _, changed, err := UpdateFile(path, true, insert); err != nil || !changed {
+		t.Fatalf("dry run: changed=%v err=%v", changed, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the dry run not to create %s", path)
+	}
+
+	if _, _, err := UpdateFile(path, false, insert); err != nil {
+		t.Fatalf("UpdateFile: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != block {
+		t.Fatalf("unexpected file content:\n%s", data)
+	}
+	if _, changed, _ := UpdateFile(path, false, insert); changed {
+		t.Fatal("expected the second setup to change nothing")
+	}
+
+	if _, changed, err := UpdateFile(path, false, RemoveBlock); err != nil || !changed {
+		t.Fatalf("remove: changed=%v err=%v", changed, err)
+	}
+	data, _ = os.ReadFile(path)
+	if len(data) != 0 {
+		t.Fatalf("expected an empty file, got:\n%s", data)
+	}
+}
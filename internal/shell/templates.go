@@ -0,0 +1,120 @@
+package shell
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/atliod/gozelle/internal/core"
+)
+
+// templates holds the scripts of each shell, one directory per shell with
+// init.tmpl and, where the shell supports them, widget.tmpl and completion.tmpl.
+//
+//go:embed templates
+var templates embed.FS
+
+// templateData is what the templates are executed with.
+type templateData struct {
+	Options
+	Exe    string // the gozelle executable, quoted for the shell
+	KeySeq string // the widget key in the shell's notation, empty for none
+}
+
+// TemplateDir returns the directory templates are overridden from:
+// $XDG_CONFIG_HOME/gozelle/templates, or ~/.config/gozelle/templates.
+// A file such as bash/widget.tmpl there replaces the built-in one.
+func TemplateDir() string {
+	dir := core.ConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "templates")
+}
+
+// templateShell returns the template directory used for shell.
+func templateShell(shell string) string {
+	if shell == "ksh" {
+		return "posix"
+	}
+	return shell
+}
+
+// loadTemplates parses the templates of shell, preferring the user's overrides.
+func loadTemplates(shell string) (*template.Template, error) {
+	dir := templateShell(shell)
+	entries, err := fs.ReadDir(templates, path.Join("templates", dir))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported shell: %s", shell)
+	}
+
+	overrides := TemplateDir()
+	root := template.New(dir)
+	for _, entry := range entries {
+		source := path.Join("templates", dir, entry.Name())
+		text, err := templates.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		if overrides != "" {
+			override := filepath.Join(overrides, dir, entry.Name())
+			if data, err := os.ReadFile(override); err == nil {
+				source, text = override, data
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+		if _, err := root.New(entry.Name()).Parse(string(text)); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", source, err)
+		}
+	}
+	return root, nil
+}
+
+// execute renders the template name of shell with opts.
+func execute(shell, name string, opts Options) (string, error) {
+	tmpl, err := loadTemplates(shell)
+	if err != nil {
+		return "", err
+	}
+	if tmpl.Lookup(name) == nil {
+		return "", fmt.Errorf("%s is not supported for %s", strings.TrimSuffix(name, ".tmpl"), shell)
+	}
+
+	data := templateData{Options: opts, Exe: quoteExe(shell, opts.Exe)}
+	if tmpl.Lookup("widget.tmpl") != nil {
+		if data.KeySeq, err = KeySequence(shell, opts.Key); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	if err := tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// quoteExe quotes the executable path for use as a command in shell.
+func quoteExe(shell, exe string) string {
+	if exe == "" {
+		return "gozelle"
+	}
+	if core.ShellQuote(exe) == exe {
+		return exe
+	}
+	switch shell {
+	case "powershell":
+		return "'" + strings.ReplaceAll(exe, "'", "''") + "'"
+	case "nushell":
+		return strconv.Quote(exe)
+	}
+	return core.ShellQuote(exe)
+}
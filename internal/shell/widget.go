@@ -0,0 +1,67 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultWidgetKey is the key the path-insertion widget is bound to.
+const DefaultWidgetKey = "ctrl-g"
+
+// KeySequence translates a key such as ctrl-g or alt-d into the notation of
+// the given shell's binding command. "none" or an empty key yields "".
+func KeySequence(shell, key string) (string, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" || key == "none" {
+		return "", nil
+	}
+
+	mod, char, ok := strings.Cut(key, "-")
+	if !ok || len(char) != 1 {
+		return "", fmt.Errorf("invalid key %q (expected ctrl-<letter> or alt-<key>)", key)
+	}
+	c := char[0]
+
+	switch mod {
+	case "ctrl":
+		if c < 'a' || c > 'z' {
+			return "", fmt.Errorf("invalid key %q (ctrl must be combined with a letter)", key)
+		}
+		switch shell {
+		case "bash":
+			return `\C-` + char, nil
+		case "zsh":
+			return "^" + strings.ToUpper(char), nil
+		case "fish":
+			return `\c` + char, nil
+		case "nushell":
+			return "modifier: control keycode: char_" + char, nil
+		case "powershell":
+			return "Ctrl+" + char, nil
+		}
+	case "alt":
+		if c <= ' ' || c > '~' || c == '\'' || c == '"' || c == '\\' {
+			return "", fmt.Errorf("invalid key %q", key)
+		}
+		switch shell {
+		case "bash", "fish":
+			return `\e` + char, nil
+		case "zsh":
+			return "^[" + char, nil
+		case "nushell":
+			return "modifier: alt keycode: char_" + char, nil
+		case "powershell":
+			return "Alt+" + char, nil
+		}
+	default:
+		return "", fmt.Errorf("invalid key %q (expected ctrl-<letter> or alt-<key>)", key)
+	}
+	return "", fmt.Errorf("unsupported shell: %s", shell)
+}
+
+// Widget returns the script defining a line-editor widget that opens the
+// interactive picker and inserts the chosen path, shell-quoted, at the cursor.
+// The widget is bound to opts.Key; with key "none" it is defined but not bound.
+func Widget(shell string, opts Options) (string, error) {
+	return execute(shell, "widget.tmpl", opts)
+}
@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestKeySequence(t *testing.T) {
+	cases := []struct {
+		shell, key, want string
+	}{
+		{"bash", "ctrl-g", `\C-g`},
+		{"zsh", "ctrl-g", "^G"},
+		{"fish", "ctrl-g", `\cg`},
+		{"bash", "alt-j", `\ej`},
+		{"zsh", "Alt-J", "^[j"},
+		{"fish", "alt-j", `\ej`},
+		{"powershell", "ctrl-g", "Ctrl+g"},
+		{"nushell", "alt-j", "modifier: alt keycode: char_j"},
+		{"bash", "none", ""},
+	}
+	for _, c := range cases {
+		got, err := KeySequence(c.shell, c.key)
+		if err != nil {
+			t.Fatalf("KeySequence(%q, %q) returned error: %v", c.shell, c.key, err)
+		}
+		if got != c.want {
+			t.Fatalf("KeySequence(%q, %q) = %q, expected %q", c.shell, c.key, got, c.want)
+		}
+	}
+
+	for _, key := range []string{"g", "ctrl-1", "shift-g", "ctrl-gg", "alt-'"} {
+		if _, err := KeySequence("bash", key); err == nil {
+			t.Fatalf("expected KeySequence(bash, %q) to fail", key)
+		}
+	}
+	if _, err := KeySequence("tcsh", "ctrl-g"); err == nil {
+		t.Fatal("expected error for unsupported shell")
+	}
+}
+
+func TestWidget(t *testing.T) {
+	script, err := Widget("zsh", Options{Key: "alt-j"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(script, "bindkey -M emacs '^[j' __gozelle_widget") {
+		t.Fatalf("expected zsh widget to be bound to alt-j, got:\n%s", script)
+	}
+
+	script, _ = Widget("fish", Options{Key: "none"})
+	if strings.Contains(script, "bind ") {
+		t.Fatalf("expected no binding with key none, got:\n%s", script)
+	}
+}
+
+func TestBashWidgetSyntax(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	script, _ := Widget("bash", Options{Key: DefaultWidgetKey})
+	out, err := exec.Command(bash, "-n", "-c", script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash widget has syntax errors: %v\n%s", err, out)
+	}
+}
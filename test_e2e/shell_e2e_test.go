@@ -0,0 +1,259 @@
+// Package test_e2e drives the scripts printed by `gozelle init` in real shells.
+// Each shell is skipped when it is not installed.
+package test_e2e
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/atliod/gozelle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// binDir holds the gozelle binary built by TestMain.
+var binDir string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gozelle-e2e")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binDir = dir
+
+	_, file, _, _ := runtime.Caller(0)
+	build := exec.Command("go", "build", "-o", filepath.Join(binDir, "gozelle"), ".")
+	build.Dir = filepath.Dir(filepath.Dir(file))
+	if out, err := build.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "building gozelle: %v\n%s", err, out)
+		os.RemoveAll(binDir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(binDir)
+	os.Exit(code)
+}
+
+// shellCase describes how to drive one shell.
+type shellCase struct {
+	name string
+	args []string // interpreter flags that skip the user's startup files
+	// source loads the init script printed for the given extra init flags.
+	source func(flags string) string
+	prompt string // what the shell runs before showing a prompt
+	// promptText expands to the prompt the shell would show.
+	promptText string
+	// complete prints "COMP=<first completion>" for `gz <word>`, empty if untested.
+	complete func(word string) string
+}
+
+var shells = []shellCase{
+	{
+		name:       "bash",
+		args:       []string{"--norc", "--noprofile"},
+		source:     func(flags string) string { return `eval "$(gozelle init bash ` + flags + `)"` },
+		prompt:     `eval "$PROMPT_COMMAND"`,
+		promptText: `$PS1`,
+		complete: func(word string) string {
+			return `COMP_WORDS=(gz ` + word + `); COMP_CWORD=1; __gozelle_complete; echo "COMP=${COMPREPLY[0]}"`
+		},
+	},
+	{
+		name:       "zsh",
+		args:       []string{"-f"},
+		source:     func(flags string) string { return `eval "$(gozelle init zsh ` + flags + `)"` },
+		prompt:     `for f in $precmd_functions; do $f; done`,
+		promptText: `$PS1`,
+	},
+	{
+		name:       "fish",
+		args:       []string{"--no-config"},
+		source:     func(flags string) string { return `gozelle init fish ` + flags + ` | source` },
+		prompt:     `emit fish_prompt`,
+		promptText: `"(fish_prompt)"`,
+		complete: func(word string) string {
+			return `echo "COMP="(complete -C "gz ` + word + `")[1]`
+		},
+	},
+}
+
+// sandbox is an isolated home, data file and directory tree for one run.
+type sandbox struct {
+	root     string // the directory tree the script moves around in
+	dataFile string
+	env      []string
+}
+
+func newSandbox(t *testing.T) *sandbox {
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	root := filepath.Join(tmp, "tree")
+	for _, dir := range []string{"projects/alpha", "projects/beta", "other"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+	}
+	home := filepath.Join(tmp, "home")
+	require.NoError(t, os.MkdirAll(home, 0o755))
+
+	s := &sandbox{root: root, dataFile: filepath.Join(tmp, "data", "db.gob")}
+	s.env = append(os.Environ(),
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		"XDG_DATA_HOME="+filepath.Join(home, ".local", "share"),
+		"GOZELLE_DATA_DIR="+s.dataFile,
+		"GOZELLE_PICKER=",
+		"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"ROOT="+root,
+	)
+	return s
+}
+
+// run executes the script lines in shell inside the sandbox and returns the
+// values of the KEY=value lines it printed.
+func (s *sandbox) run(t *testing.T, sh shellCase, lines ...string) map[string][]string {
+	path, err := exec.LookPath(sh.name)
+	if err != nil {
+		t.Skipf("%s not installed", sh.name)
+	}
+	cmd := exec.Command(path, append(sh.args, "-c", strings.Join(lines, "\n"))...)
+	cmd.Dir = s.root
+	cmd.Env = s.env
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "%s failed:\n%s", sh.name, out)
+
+	values := map[string][]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok && key == strings.ToUpper(key) {
+			values[key] = append(values[key], value)
+		}
+	}
+	return values
+}
+
+// scores returns the scores of the entries in the sandbox's store by path.
+func (s *sandbox) scores(t *testing.T) map[string]db.Score {
+	dm, err := db.NewDirectoryManagerWithPath(s.dataFile)
+	require.NoError(t, err)
+	scores := map[string]db.Score{}
+	for _, dir := range dm.Entries {
+		scores[dir.Path] += dir.Score
+	}
+	return scores
+}
+
+// stored returns the set of paths in the sandbox's store.
+func (s *sandbox) stored(t *testing.T) map[string]bool {
+	dm, err := db.NewDirectoryManagerWithPath(s.dataFile)
+	require.NoError(t, err)
+	paths := map[string]bool{}
+	for _, dir := range dm.Entries {
+		paths[dir.Path] = true
+	}
+	return paths
+}
+
+func TestShellJumps(t *testing.T) {
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			s := newSandbox(t)
+			visit := func(dir string) string { return `cd "$ROOT/` + dir + `"; ` + sh.prompt }
+			lines := []string{
+				sh.source(""),
+				visit("projects/alpha"),
+				visit("other"),
+				visit("projects/alpha"),
+				visit("projects/beta"),
+				`cd /`,
+				`gz alpha; echo "PWD=$PWD"`,
+				`gz bet; echo "PWD=$PWD"`,
+				`gz -; echo "PWD=$PWD"`,
+				`gz "$ROOT/other"; echo "PWD=$PWD"`,
+				`gz nosuchkeyword; echo "PWD=$PWD"`,
+			}
+			if sh.complete != nil {
+				lines = append(lines, sh.complete("alph"))
+			}
+			values := s.run(t, sh, lines...)
+
+			alpha := filepath.Join(s.root, "projects", "alpha")
+			beta := filepath.Join(s.root, "projects", "beta")
+			other := filepath.Join(s.root, "other")
+			assert.Equal(t, []string{alpha, beta, alpha, other, other}, values["PWD"])
+			if sh.complete != nil {
+				assert.Equal(t, []string{alpha}, values["COMP"])
+			}
+
+			stored := s.stored(t)
+			for _, dir := range []string{alpha, beta, other} {
+				assert.True(t, stored[dir], "expected %s in the store, got %v", dir, stored)
+			}
+
+			dm, err := db.NewDirectoryManagerWithPath(s.dataFile)
+			require.NoError(t, err)
+			entry, err := dm.Get(alpha)
+			require.NoError(t, err)
+			assert.Equal(t, 1, entry.Visits.Jump, "expected gz alpha to count as a jump")
+			assert.Positive(t, entry.Visits.Cd)
+		})
+	}
+}
+
+func TestShellInitOptions(t *testing.T) {
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			s := newSandbox(t)
+			values := s.run(t, sh,
+				sh.source("--cmd j --hook none --key none"),
+				`cd "$ROOT/projects/alpha"; `+sh.prompt,
+				`cd /`,
+				`j "$ROOT/other"; echo "PWD=$PWD"`,
+			)
+			assert.Equal(t, []string{filepath.Join(s.root, "other")}, values["PWD"])
+			assert.Empty(t, s.stored(t), "expected --hook none to record nothing")
+		})
+	}
+}
+
+func TestShellIncognito(t *testing.T) {
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			s := newSandbox(t)
+			visit := func(dir string) string { return `cd "$ROOT/` + dir + `"; ` + sh.prompt }
+			values := s.run(t, sh,
+				sh.source(""),
+				visit("projects/alpha"),
+				`gozelle incognito on`,
+				`echo "STATE=$(gozelle incognito)"`,
+				`echo "PROMPT=`+sh.promptText+`"`,
+				visit("other"),
+				`cd /`,
+				`gz alpha; echo "PWD=$PWD"`,
+				`gozelle incognito off`,
+				`echo "STATE=$(gozelle incognito)"`,
+				`echo "PROMPT=`+sh.promptText+`"`,
+				visit("projects/beta"),
+			)
+
+			alpha := filepath.Join(s.root, "projects", "alpha")
+			beta := filepath.Join(s.root, "projects", "beta")
+			assert.Equal(t, []string{"on", "off"}, values["STATE"])
+			assert.Equal(t, []string{alpha}, values["PWD"])
+			require.Len(t, values["PROMPT"], 2)
+			assert.True(t, strings.HasPrefix(values["PROMPT"][0], "(incognito) "), "prompt %q is not marked", values["PROMPT"][0])
+			assert.NotContains(t, values["PROMPT"][1], "(incognito)")
+
+			scores := s.scores(t)
+			assert.Equal(t, map[string]db.Score{
+				alpha: db.NewDirectory(alpha).Score,
+				beta:  db.NewDirectory(beta).Score,
+			}, scores, "expected nothing recorded while incognito")
+		})
+	}
+}